@@ -32,6 +32,9 @@ const (
 	EXPORTED_ENTITY = 7
 	ATTLIST         = 8
 	NOTATION        = 9
+	CONDITIONAL     = 10
+	PI              = 11
+	REFERENCE       = 12
 
 	// string type
 	CDATA = 20
@@ -48,6 +51,18 @@ const (
 	//Enumerated Attribute Type:	Attribute Description:
 	ENUM_NOTATION = 37
 	ENUM_ENUM     = 38
+
+	// Attribute default declaration
+	DEFAULT_VALUE    = 60
+	DEFAULT_REQUIRED = 61
+	DEFAULT_IMPLIED  = 62
+	DEFAULT_FIXED    = 63
+)
+
+// Quote delimiters of a literal
+const (
+	DoubleQuote = "\""
+	SingleQuote = "'"
 )
 
 type DTDExtra struct {
@@ -59,15 +74,40 @@ type DTDExtra struct {
 	Url         string
 	PublicID    string
 	SystemID    string
+	Quote       string
+	UrlQuote    string
 }
 
 // IDTDBlock Interface for DTD block
+// Kind returns one of the block constants, XMLDECL to REFERENCE.
+// The other capabilities of a block are given by the interfaces below,
+// use a type assertion to check them.
 type IDTDBlock interface {
-	GetName() string
+	Kind() int
+	Pos() Pos
 	Render() string
-	SetExported(v bool)
+}
+
+// Named is implemented by the blocks having a name:
+// elements, attribute lists, entities, notations, processing instructions (their target)
+// and parameter entity references
+type Named interface {
+	GetName() string
+}
+
+// Valued is implemented by the blocks having a value:
+// elements (their content model), attribute lists, entities, comments,
+// processing instructions, conditional sections (their keyword) and text declarations
+type Valued interface {
 	GetValue() string
-	GetExtra() *DTDExtra
+}
+
+// Exportable is implemented by the blocks that can be referenced right after
+// their declaration: parameter entities
+// The parser records references as Reference blocks, where they appear.
+type Exportable interface {
+	SetExported(v bool)
+	IsExported() bool
 }
 
 // Helper to join strings
@@ -79,9 +119,35 @@ func join(strs ...string) string {
 	return sb.String()
 }
 
+// Delimiter returns the quote to delimit the literal s
+// q is used when it is set and not found in s, the other quote otherwise
+// @ref https://www.w3.org/TR/xml11/#sec-common-syn
+func Delimiter(s string, q string) string {
+	if q != SingleQuote {
+		q = DoubleQuote
+	}
+
+	if !strings.Contains(s, q) {
+		return q
+	}
+
+	if q == DoubleQuote {
+		return SingleQuote
+	}
+	return DoubleQuote
+}
+
+// Quote returns the literal s delimited by the quote given by Delimiter
+func Quote(s string, q string) string {
+	d := Delimiter(s, q)
+	return d + s + d
+}
+
 // Translate convert block type constant to a name
 func Translate(i int) string {
 	switch i {
+	case XMLDECL:
+		return "XMLDecl"
 	case ATTRIBUTE:
 		return "Attribute"
 	case COMMENT:
@@ -96,8 +162,14 @@ func Translate(i int) string {
 		return "Attlist"
 	case NOTATION:
 		return "Notation"
+	case CONDITIONAL:
+		return "Conditional"
+	case PI:
+		return "ProcessingInstruction"
+	case REFERENCE:
+		return "Reference"
 	default:
-		panic("Unknown type" + fmt.Sprintf("%d", i) + " requested")
+		return "Unknown type " + fmt.Sprintf("%d", i)
 	}
 }
 
@@ -6,16 +6,25 @@
 package DTD
 
 // Attlist represents an attlist
+// Value holds the attribute definitions as written when they use parameter entity
+// references in place of a type or a default value, Attributes is then empty
+// until the attlist is expanded. ValuePosition is the position of Value in the DTD.
 type Attlist struct {
-	Name       string
-	Value      string
-	Attributes []Attribute
-	Entities   []string
+	Name          string      `json:"name"`
+	Value         string      `json:"value,omitempty"`
+	ValuePosition *Pos        `json:"valuePosition,omitempty"`
+	Attributes    []Attribute `json:"attributes"`
+	Entities      []string    `json:"-"`
+	Position      Pos         `json:"position"`
 }
 
 // Render an Attlist
 // implements IDTDBlock
 func (a *Attlist) Render() string {
+	if a.Value != "" {
+		return join("<!ATTLIST ", a.Name, " ", a.Value, ">\n")
+	}
+
 	attributes := "\n"
 
 	for _, attr := range a.Attributes {
@@ -26,29 +35,17 @@ func (a *Attlist) Render() string {
 }
 
 // GetName Get the name
-// implements IDTDBlock
+// implements Named
 func (a *Attlist) GetName() string {
 	return a.Name
 }
 
-// SetExported set the current entity to exported
-// implements IDTDBlock
-func (a *Attlist) SetExported(v bool) {
-	panic("A comment should never be set as exported")
-}
-
 // GetValue Get the value
-// implements IDTDBlock
+// implements Valued
 func (a *Attlist) GetValue() string {
 	return a.Value
 }
 
-// GetExported Unused, tells if the comment was exported
-// implements IDTDBlock
-func (a *Attlist) GetExported() bool {
-	panic("Attlist are not exported")
-}
-
 // GetExtra Get extrainformation
 func (a *Attlist) GetExtra() *DTDExtra {
 	var extra DTDExtra
@@ -56,6 +53,18 @@ func (a *Attlist) GetExtra() *DTDExtra {
 	return &extra
 }
 
+// Kind returns ATTLIST
+// implements IDTDBlock
+func (a *Attlist) Kind() int {
+	return ATTLIST
+}
+
+// Pos Get the position of the attlist
+// implements IDTDBlock
+func (a *Attlist) Pos() Pos {
+	return a.Position
+}
+
 // IsAttlistType check if the interface is a DTD.Comment
 func IsAttlistType(i interface{}) bool {
 	switch i.(type) {
@@ -12,48 +12,71 @@ import (
 )
 
 // Attribute represents an attribute
+// @ref https://www.w3.org/TR/xml11/#attdecls
+//
+// Enumeration holds the values of an enumerated type, DefaultKind tells how the
+// default is declared (DEFAULT_VALUE, DEFAULT_REQUIRED, DEFAULT_IMPLIED or DEFAULT_FIXED)
+// and DefaultValue holds the literal of DEFAULT_VALUE and DEFAULT_FIXED.
+// Quote is the delimiter of this literal.
+//
+// Value holds the default value or the enumeration when there is none,
+// or the reference of an attribute declared with a parameter entity.
 type Attribute struct {
-	Name     string
-	Type     int
-	Default  string
-	Value    string
-	Implied  bool
-	Required bool
-	Fixed    bool
-	IsEntity bool
+	Name         string   `json:"name,omitempty"`
+	Type         int      `json:"-"`
+	Default      string   `json:"-"`
+	Value        string   `json:"value,omitempty"`
+	Enumeration  []string `json:"enumeration,omitempty"`
+	DefaultKind  int      `json:"-"`
+	DefaultValue string   `json:"defaultValue,omitempty"`
+	Quote        string   `json:"quote,omitempty"`
+	Implied      bool     `json:"-"`
+	Required     bool     `json:"-"`
+	Fixed        bool     `json:"-"`
+	IsEntity     bool     `json:"isEntity,omitempty"`
+	Position     Pos      `json:"position"`
 }
 
 // Render an Attribute
 func (a *Attribute) Render() string {
-	s := "\t"
-
-	if a.Name != "" {
-		s += a.Name + "\t"
+	if a.IsEntity {
+		return "\t" + a.Value + "\n"
 	}
 
-	s += AttributeType(a.Type) + " "
-
-	if a.Fixed {
-		s += " #FIXED "
-	}
+	return join("\t", a.Name, "\t", a.RenderType(), " ", a.RenderDefault(), "\n")
+}
 
-	if a.Value != "" && !a.IsEntity {
-		s += "\"" + a.Value + "\""
-	} else if a.Value != "" && a.IsEntity {
-		s += a.Value
-	}
+// RenderType Render the type of the attribute, enumerated values included
+//
+// [57]   	EnumeratedType	   ::=   	NotationType | Enumeration
+func (a *Attribute) RenderType() string {
+	enumeration := "(" + strings.Join(a.Enumeration, "|") + ")"
 
-	if a.Implied {
-		s += " #IMPLIED "
+	switch a.Type {
+	case ENUM_ENUM:
+		return enumeration
+	case ENUM_NOTATION:
+		return "NOTATION " + enumeration
 	}
+	return AttributeType(a.Type)
+}
 
-	if a.Required {
-		s += " #REQUIRED "
+// RenderDefault Render the default declaration of the attribute
+//
+// [60]   	DefaultDecl	   ::=   	'#REQUIRED' | '#IMPLIED' | (('#FIXED' S)? AttValue)
+func (a *Attribute) RenderDefault() string {
+	switch a.DefaultKind {
+	case DEFAULT_REQUIRED:
+		return "#REQUIRED"
+	case DEFAULT_IMPLIED:
+		return "#IMPLIED"
+	case DEFAULT_FIXED:
+		return "#FIXED " + Quote(a.DefaultValue, a.Quote)
+	case DEFAULT_VALUE:
+		return Quote(a.DefaultValue, a.Quote)
 	}
-
-	s += "\n"
-
-	return s
+	log.Debugf("No default declaration for attribute '%s'", a.Name)
+	return ""
 }
 
 // GetExported Unused, tells if the comment was exported
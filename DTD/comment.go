@@ -7,8 +7,9 @@ package DTD
 
 // Comment represents a comment
 type Comment struct {
-	Value    string
-	Exported bool
+	Value    string `json:"value"`
+	Exported bool   `json:"exported,omitempty"`
+	Position Pos    `json:"position"`
 }
 
 // Render an entity
@@ -17,20 +18,8 @@ func (c *Comment) Render() string {
 	return "<!--" + c.Value + "-->"
 }
 
-// GetName Get the name
-// implements IDTDBlock
-func (c *Comment) GetName() string {
-	return "comment"
-}
-
-// SetExported set the current entity to exported
-// implements IDTDBlock
-func (c *Comment) SetExported(v bool) {
-	panic("A comment should never be set as exported")
-}
-
 // GetValue Get the value
-// implements IDTDBlock
+// implements Valued
 func (c *Comment) GetValue() string {
 	return c.Value
 }
@@ -41,6 +30,18 @@ func (c *Comment) GetExtra() *DTDExtra {
 	return &extra
 }
 
+// Kind returns COMMENT
+// implements IDTDBlock
+func (c *Comment) Kind() int {
+	return COMMENT
+}
+
+// Pos Get the position of the comment
+// implements IDTDBlock
+func (c *Comment) Pos() Pos {
+	return c.Position
+}
+
 // IsCommentType check if the interface is a DTD.Comment
 func IsCommentType(i interface{}) bool {
 	switch i.(type) {
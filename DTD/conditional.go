@@ -0,0 +1,102 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConditionalSection represents a conditional section
+//
+// [61] conditionalSect ::= includeSect | ignoreSect
+// [62] includeSect     ::= '<![' S? 'INCLUDE' S? '[' extSubsetDecl ']]>'
+// [63] ignoreSect      ::= '<![' S? 'IGNORE' S? '[' ignoreSectContents* ']]>'
+//
+// Keyword is INCLUDE, IGNORE or a parameter entity reference such as %local.mode;
+// Content is the raw content of the section. Blocks are the declarations found
+// in the section, they are not parsed when the keyword is IGNORE.
+type ConditionalSection struct {
+	Keyword  string      `json:"keyword"`
+	Content  string      `json:"content,omitempty"`
+	Blocks   []IDTDBlock `json:"blocks"`
+	Position Pos         `json:"position"`
+}
+
+// Render a conditional section
+// implements IDTDBlock
+func (c *ConditionalSection) Render() string {
+	return join("<![", c.Keyword, "[", c.Content, "]]>")
+}
+
+// GetValue Get the keyword
+// implements Valued
+func (c *ConditionalSection) GetValue() string {
+	return c.Keyword
+}
+
+// GetExtra Get extrainformation
+func (c *ConditionalSection) GetExtra() *DTDExtra {
+	var extra DTDExtra
+	return &extra
+}
+
+// IsParameter tells if the keyword is a parameter entity reference
+func (c *ConditionalSection) IsParameter() bool {
+	return strings.HasPrefix(c.Keyword, "%") && strings.HasSuffix(c.Keyword, ";")
+}
+
+// EntityName returns the name of the parameter entity used as keyword
+func (c *ConditionalSection) EntityName() string {
+	if !c.IsParameter() {
+		return ""
+	}
+	return strings.TrimSpace(c.Keyword[1 : len(c.Keyword)-1])
+}
+
+// Included evaluates the keyword
+// lookup returns the replacement text of a parameter entity
+func (c *ConditionalSection) Included(lookup func(name string) (string, bool)) (bool, error) {
+	keyword := c.Keyword
+
+	if c.IsParameter() {
+		value, ok := lookup(c.EntityName())
+		if !ok {
+			return false, fmt.Errorf("parameter entity '%s' is not declared", c.EntityName())
+		}
+		keyword = value
+	}
+
+	switch strings.TrimSpace(keyword) {
+	case "INCLUDE":
+		return true, nil
+	case "IGNORE":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid conditional section keyword '%s'", keyword)
+}
+
+// Kind returns CONDITIONAL
+// implements IDTDBlock
+func (c *ConditionalSection) Kind() int {
+	return CONDITIONAL
+}
+
+// Pos Get the position of the conditional section
+// implements IDTDBlock
+func (c *ConditionalSection) Pos() Pos {
+	return c.Position
+}
+
+// IsConditionalSectionType check if the interface is a DTD.ConditionalSection
+func IsConditionalSectionType(i interface{}) bool {
+	switch i.(type) {
+	case *ConditionalSection:
+		return true
+	default:
+		return false
+	}
+}
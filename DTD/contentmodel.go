@@ -0,0 +1,141 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+import "strings"
+
+const (
+	// Content specification type
+	// [46] contentspec ::= 'EMPTY' | 'ANY' | Mixed | children
+	CONTENT_EMPTY    = 40
+	CONTENT_ANY      = 41
+	CONTENT_MIXED    = 42
+	CONTENT_CHILDREN = 43
+	// the whole content specification is a parameter entity reference
+	CONTENT_ENTITY = 44
+
+	// Particle type
+	PARTICLE_NAME     = 50
+	PARTICLE_SEQUENCE = 51
+	PARTICLE_CHOICE   = 52
+	PARTICLE_PCDATA   = 53
+	// unexpanded parameter entity reference
+	PARTICLE_ENTITY = 54
+
+	// Occurrence indicator
+	ONCE         = 0
+	OPTIONAL     = 1 // ?
+	ZERO_OR_MORE = 2 // *
+	ONE_OR_MORE  = 3 // +
+)
+
+// ContentModel represents the content specification of an element
+//
+// For CONTENT_CHILDREN and CONTENT_MIXED, Root holds the top level group.
+// A Mixed content model is represented as a choice whose first particle
+// is PARTICLE_PCDATA.
+// For CONTENT_ENTITY, Root holds the PARTICLE_ENTITY reference.
+type ContentModel struct {
+	Type int       `json:"-"`
+	Root *Particle `json:"root,omitempty"`
+}
+
+// Particle represents a node of a content model: a name, a group or a
+// parameter entity reference
+type Particle struct {
+	Type       int         `json:"-"`
+	Name       string      `json:"name,omitempty"`
+	Occurrence int         `json:"-"`
+	Children   []*Particle `json:"children,omitempty"`
+}
+
+// Render a content model
+func (c *ContentModel) Render() string {
+	switch c.Type {
+	case CONTENT_EMPTY:
+		return "EMPTY"
+	case CONTENT_ANY:
+		return "ANY"
+	}
+	if c.Root == nil {
+		return ""
+	}
+	return c.Root.Render()
+}
+
+// Names returns the element names referenced in the content model,
+// in order of first appearance
+func (c *ContentModel) Names() []string {
+	if c.Root == nil {
+		return nil
+	}
+	return c.Root.Names()
+}
+
+// Names returns the element names referenced in the particle,
+// in order of first appearance
+func (p *Particle) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	var collect func(p *Particle)
+	collect = func(p *Particle) {
+		if p.Type == PARTICLE_NAME && !seen[p.Name] {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+		for _, child := range p.Children {
+			collect(child)
+		}
+	}
+	collect(p)
+
+	return names
+}
+
+// IsGroup tells if the particle is a sequence or a choice
+func (p *Particle) IsGroup() bool {
+	return p.Type == PARTICLE_SEQUENCE || p.Type == PARTICLE_CHOICE
+}
+
+// Render a particle
+func (p *Particle) Render() string {
+	var s string
+
+	switch p.Type {
+	case PARTICLE_NAME:
+		s = p.Name
+	case PARTICLE_PCDATA:
+		s = "#PCDATA"
+	case PARTICLE_ENTITY:
+		s = "%" + p.Name + ";"
+	case PARTICLE_SEQUENCE, PARTICLE_CHOICE:
+		sep := ","
+		if p.Type == PARTICLE_CHOICE {
+			sep = "|"
+		}
+		children := make([]string, len(p.Children))
+		for i, child := range p.Children {
+			children[i] = child.Render()
+		}
+		s = join("(", strings.Join(children, sep), ")")
+	}
+
+	return s + Occurrence(p.Occurrence)
+}
+
+// Occurrence convert an occurrence indicator (int) to its corresponding string value
+func Occurrence(o int) string {
+	switch o {
+	case OPTIONAL:
+		return "?"
+	case ZERO_OR_MORE:
+		return "*"
+	case ONE_OR_MORE:
+		return "+"
+	}
+	return ""
+}
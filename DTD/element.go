@@ -7,8 +7,10 @@ package DTD
 
 // Element represents a DTD element
 type Element struct {
-	Name  string
-	Value string
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Content  *ContentModel `json:"content,omitempty"`
+	Position Pos           `json:"position"`
 }
 
 // Render an Element
@@ -18,19 +20,13 @@ func (e *Element) Render() string {
 }
 
 // GetName Get the name
-// implements IDTDBlock
+// implements Named
 func (e *Element) GetName() string {
 	return e.Name
 }
 
-// SetExported set the current entity to exported
-// implements IDTDBlock
-func (e *Element) SetExported(v bool) {
-	panic("An element should never be set as exported")
-}
-
 // GetValue Get the value
-// implements IDTDBlock
+// implements Valued
 func (e *Element) GetValue() string {
 	return e.Value
 }
@@ -41,6 +37,18 @@ func (e *Element) GetExtra() *DTDExtra {
 	return &extra
 }
 
+// Kind returns ELEMENT
+// implements IDTDBlock
+func (e *Element) Kind() int {
+	return ELEMENT
+}
+
+// Pos Get the position of the element
+// implements IDTDBlock
+func (e *Element) Pos() Pos {
+	return e.Position
+}
+
 // IsElementType check if the interface is a DTD.Element
 func IsElementType(i interface{}) bool {
 	switch i.(type) {
@@ -8,17 +8,23 @@ package DTD
 import "go.uber.org/zap/zapcore"
 
 // Entity representss a DTD Entity
+// Quote and UrlQuote are the delimiters of the literals of Value and Url
+// Notation is the notation of an unparsed entity, declared with NDATA
 type Entity struct {
-	Parameter  bool
-	IsExternal bool
-	IsInternal bool
-	Name       string
-	Value      string
-	Public     bool
-	System     bool
-	Url        string
-	Exported   bool
-	Attributes []Attribute
+	Parameter  bool        `json:"parameter,omitempty"`
+	IsExternal bool        `json:"isExternal,omitempty"`
+	IsInternal bool        `json:"-"`
+	Name       string      `json:"name"`
+	Value      string      `json:"value,omitempty"`
+	Quote      string      `json:"quote,omitempty"`
+	Public     bool        `json:"public,omitempty"`
+	System     bool        `json:"system,omitempty"`
+	Url        string      `json:"url,omitempty"`
+	UrlQuote   string      `json:"urlQuote,omitempty"`
+	Notation   string      `json:"notation,omitempty"`
+	Exported   bool        `json:"exported,omitempty"`
+	Attributes []Attribute `json:"-"`
+	Position   Pos         `json:"position"`
 }
 
 func (e Entity) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -52,26 +58,42 @@ func (e Entity) Render() string {
 	}
 
 	if e.Url != "" {
-		url = "\"" + e.Url + "\""
+		url = " " + Quote(e.Url, e.UrlQuote)
 	}
 
-	return join("<!ENTITY", m, e.Name, " ", eType, "\"", e.Value, "\"", url, "\n>", exportedStr, "\n")
+	if e.Notation != "" {
+		url += " NDATA " + e.Notation
+	}
+
+	// a system entity has no value
+	value := Quote(e.Value, e.Quote)
+	if e.System && !e.Public {
+		value = ""
+	}
+
+	return join("<!ENTITY", m, e.Name, " ", eType, value, url, "\n>", exportedStr, "\n")
 }
 
 // GetName Get the name
-// implements IDTDBlock
+// implements Named
 func (e *Entity) GetName() string {
 	return e.Name
 }
 
 // SetExported set the current entity to exported
-// implements IDTDBlock
+// implements Exportable
 func (e *Entity) SetExported(v bool) {
 	e.Exported = v
 }
 
+// IsExported tells if the entity is referenced right after its declaration
+// implements Exportable
+func (e *Entity) IsExported() bool {
+	return e.Exported
+}
+
 // GetValue Get the value
-// implements IDTDBlock
+// implements Valued
 func (e *Entity) GetValue() string {
 	return e.Value
 }
@@ -84,9 +106,23 @@ func (e *Entity) GetExtra() *DTDExtra {
 	extra.IsPublic = e.Public
 	extra.IsSystem = e.System
 	extra.Url = e.Url
+	extra.Quote = e.Quote
+	extra.UrlQuote = e.UrlQuote
 	return &extra
 }
 
+// Kind returns ENTITY
+// implements IDTDBlock
+func (e *Entity) Kind() int {
+	return ENTITY
+}
+
+// Pos Get the position of the entity
+// implements IDTDBlock
+func (e *Entity) Pos() Pos {
+	return e.Position
+}
+
 // IsEntityType check if the interface is a DTD.ExportedEntity
 func IsEntityType(i interface{}) bool {
 	switch i.(type) {
@@ -0,0 +1,427 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Names of the block kinds, used as the "kind" discriminator of the JSON blocks
+var kindNames = map[int]string{
+	XMLDECL:     "xmldecl",
+	PI:          "pi",
+	COMMENT:     "comment",
+	ELEMENT:     "element",
+	ATTLIST:     "attlist",
+	ENTITY:      "entity",
+	NOTATION:    "notation",
+	CONDITIONAL: "conditional",
+	REFERENCE:   "reference",
+}
+
+// Names of the enumerated values written in JSON
+var (
+	contentTypeNames = map[int]string{
+		CONTENT_EMPTY:    "empty",
+		CONTENT_ANY:      "any",
+		CONTENT_MIXED:    "mixed",
+		CONTENT_CHILDREN: "children",
+		CONTENT_ENTITY:   "entity",
+	}
+	particleTypeNames = map[int]string{
+		PARTICLE_NAME:     "name",
+		PARTICLE_SEQUENCE: "sequence",
+		PARTICLE_CHOICE:   "choice",
+		PARTICLE_PCDATA:   "pcdata",
+		PARTICLE_ENTITY:   "entity",
+	}
+	occurrenceNames = map[int]string{
+		ONCE:         "once",
+		OPTIONAL:     "optional",
+		ZERO_OR_MORE: "zeroOrMore",
+		ONE_OR_MORE:  "oneOrMore",
+	}
+	attributeTypeNames = map[int]string{
+		CDATA:          "CDATA",
+		TOKEN_ID:       "ID",
+		TOKEN_IDREF:    "IDREF",
+		TOKEN_IDREFS:   "IDREFS",
+		TOKEN_ENTITY:   "ENTITY",
+		TOKEN_ENTITIES: "ENTITIES",
+		TOKEN_NMTOKEN:  "NMTOKEN",
+		TOKEN_NMTOKENS: "NMTOKENS",
+		ENUM_NOTATION:  "NOTATION",
+		ENUM_ENUM:      "enumeration",
+	}
+	defaultKindNames = map[int]string{
+		DEFAULT_VALUE:    "value",
+		DEFAULT_REQUIRED: "required",
+		DEFAULT_IMPLIED:  "implied",
+		DEFAULT_FIXED:    "fixed",
+	}
+)
+
+// KindName returns the name of a block kind as written in JSON
+func KindName(kind int) string {
+	return kindNames[kind]
+}
+
+// enumValue returns the value named name in names, an empty name is 0
+func enumValue(what string, names map[int]string, name string) (int, error) {
+	if name == "" {
+		return 0, nil
+	}
+
+	for v, n := range names {
+		if n == name {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s '%s'", what, name)
+}
+
+// marshal returns the JSON encoding of v
+// markup characters are not escaped, values of a DTD are full of them
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// newBlock returns a new block for the kind named name
+func newBlock(name string) (IDTDBlock, error) {
+	switch name {
+	case "xmldecl":
+		return &XMLDecl{}, nil
+	case "pi":
+		return &ProcessingInstruction{}, nil
+	case "comment":
+		return &Comment{}, nil
+	case "element":
+		return &Element{}, nil
+	case "attlist":
+		return &Attlist{}, nil
+	case "entity":
+		return &Entity{}, nil
+	case "notation":
+		return &Notation{}, nil
+	case "conditional":
+		return &ConditionalSection{}, nil
+	case "reference":
+		return &Reference{}, nil
+	}
+	return nil, fmt.Errorf("unknown block kind '%s'", name)
+}
+
+// UnmarshalBlock decodes a JSON block, its type is given by its "kind"
+func UnmarshalBlock(data []byte) (IDTDBlock, error) {
+	var k struct {
+		Kind string `json:"kind"`
+	}
+
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+
+	b, err := newBlock(k.Kind)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UnmarshalBlocks decodes a JSON array of blocks
+func UnmarshalBlocks(data []byte) ([]IDTDBlock, error) {
+	var raw []json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return unmarshalBlocks(raw)
+}
+
+// unmarshalBlocks decodes each block of raw
+func unmarshalBlocks(raw []json.RawMessage) ([]IDTDBlock, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	blocks := make([]IDTDBlock, 0, len(raw))
+
+	for _, r := range raw {
+		b, err := UnmarshalBlock(r)
+
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (x *XMLDecl) MarshalJSON() ([]byte, error) {
+	type xmlDecl XMLDecl
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*xmlDecl
+	}{KindName(XMLDECL), (*xmlDecl)(x)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (pi *ProcessingInstruction) MarshalJSON() ([]byte, error) {
+	type processingInstruction ProcessingInstruction
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*processingInstruction
+	}{KindName(PI), (*processingInstruction)(pi)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (c *Comment) MarshalJSON() ([]byte, error) {
+	type comment Comment
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*comment
+	}{KindName(COMMENT), (*comment)(c)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (e *Element) MarshalJSON() ([]byte, error) {
+	type element Element
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*element
+	}{KindName(ELEMENT), (*element)(e)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (c *ContentModel) MarshalJSON() ([]byte, error) {
+	type contentModel ContentModel
+	return marshal(struct {
+		Type string `json:"type"`
+		*contentModel
+	}{contentTypeNames[c.Type], (*contentModel)(c)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *ContentModel) UnmarshalJSON(data []byte) error {
+	type contentModel ContentModel
+	aux := struct {
+		Type string `json:"type"`
+		*contentModel
+	}{contentModel: (*contentModel)(c)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	c.Type, err = enumValue("content type", contentTypeNames, aux.Type)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+func (p *Particle) MarshalJSON() ([]byte, error) {
+	type particle Particle
+	return marshal(struct {
+		Type       string `json:"type"`
+		Occurrence string `json:"occurrence"`
+		*particle
+	}{particleTypeNames[p.Type], occurrenceNames[p.Occurrence], (*particle)(p)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *Particle) UnmarshalJSON(data []byte) error {
+	type particle Particle
+	aux := struct {
+		Type       string `json:"type"`
+		Occurrence string `json:"occurrence"`
+		*particle
+	}{particle: (*particle)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+
+	if p.Type, err = enumValue("particle type", particleTypeNames, aux.Type); err != nil {
+		return err
+	}
+	p.Occurrence, err = enumValue("occurrence", occurrenceNames, aux.Occurrence)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+func (a *Attlist) MarshalJSON() ([]byte, error) {
+	type attlist Attlist
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*attlist
+	}{KindName(ATTLIST), (*attlist)(a)})
+}
+
+// MarshalJSON implements json.Marshaler
+// the flags of the default declaration are given by defaultKind
+func (a Attribute) MarshalJSON() ([]byte, error) {
+	return marshal(struct {
+		Name         string   `json:"name,omitempty"`
+		Type         string   `json:"type,omitempty"`
+		Enumeration  []string `json:"enumeration,omitempty"`
+		DefaultKind  string   `json:"defaultKind,omitempty"`
+		DefaultValue string   `json:"defaultValue,omitempty"`
+		Quote        string   `json:"quote,omitempty"`
+		Value        string   `json:"value,omitempty"`
+		IsEntity     bool     `json:"isEntity,omitempty"`
+		Position     Pos      `json:"position"`
+	}{a.Name, attributeTypeNames[a.Type], a.Enumeration, defaultKindNames[a.DefaultKind], a.DefaultValue, a.Quote, a.Value, a.IsEntity, a.Position})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Attribute) UnmarshalJSON(data []byte) error {
+	type attribute Attribute
+	aux := struct {
+		Type        string `json:"type"`
+		DefaultKind string `json:"defaultKind"`
+		*attribute
+	}{attribute: (*attribute)(a)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+
+	if a.Type, err = enumValue("attribute type", attributeTypeNames, aux.Type); err != nil {
+		return err
+	}
+
+	if a.DefaultKind, err = enumValue("default kind", defaultKindNames, aux.DefaultKind); err != nil {
+		return err
+	}
+
+	a.Required = a.DefaultKind == DEFAULT_REQUIRED
+	a.Implied = a.DefaultKind == DEFAULT_IMPLIED
+	a.Fixed = a.DefaultKind == DEFAULT_FIXED
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (e *Entity) MarshalJSON() ([]byte, error) {
+	type entity Entity
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*entity
+	}{KindName(ENTITY), (*entity)(e)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (n *Notation) MarshalJSON() ([]byte, error) {
+	type notation Notation
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*notation
+	}{KindName(NOTATION), (*notation)(n)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (c *ConditionalSection) MarshalJSON() ([]byte, error) {
+	type section ConditionalSection
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*section
+	}{KindName(CONDITIONAL), (*section)(c)})
+}
+
+// MarshalJSON implements json.Marshaler
+func (r *Reference) MarshalJSON() ([]byte, error) {
+	type reference Reference
+	return marshal(struct {
+		Kind string `json:"kind"`
+		*reference
+	}{KindName(REFERENCE), (*reference)(r)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+// the blocks of the section are decoded according to their kind
+func (c *ConditionalSection) UnmarshalJSON(data []byte) error {
+	type section ConditionalSection
+	aux := struct {
+		*section
+		Blocks []json.RawMessage `json:"blocks"`
+	}{section: (*section)(c)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	blocks, err := unmarshalBlocks(aux.Blocks)
+	c.Blocks = blocks
+	return err
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+// the blocks of the module are decoded according to their kind
+func (m *Module) UnmarshalJSON(data []byte) error {
+	type module Module
+	aux := struct {
+		*module
+		Blocks []json.RawMessage `json:"blocks"`
+	}{module: (*module)(m)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	blocks, err := unmarshalBlocks(aux.Blocks)
+	m.Blocks = blocks
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+// only the include tree and the blocks are written, the indexes are built again
+// when the schema is decoded.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return marshal(struct {
+		Root   *Module     `json:"root"`
+		Blocks []IDTDBlock `json:"blocks"`
+	}{s.Root, s.Blocks})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Root   *Module           `json:"root"`
+		Blocks []json.RawMessage `json:"blocks"`
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	blocks, err := unmarshalBlocks(aux.Blocks)
+
+	if err != nil {
+		return err
+	}
+
+	*s = *NewSchema(aux.Root, blocks)
+	return nil
+}
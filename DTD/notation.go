@@ -6,12 +6,16 @@
 package DTD
 
 // Notation reprensents a notation
+// PublicIDQuote and SystemIDQuote are the delimiters of the literals of the identifiers
 type Notation struct {
-	Name     string
-	Public   bool
-	System   bool
-	PublicID string
-	SystemID string
+	Name          string `json:"name"`
+	Public        bool   `json:"public,omitempty"`
+	System        bool   `json:"system,omitempty"`
+	PublicID      string `json:"publicId,omitempty"`
+	PublicIDQuote string `json:"publicIdQuote,omitempty"`
+	SystemID      string `json:"systemId,omitempty"`
+	SystemIDQuote string `json:"systemIdQuote,omitempty"`
+	Position      Pos    `json:"position"`
 }
 
 // Render an Notation
@@ -24,32 +28,20 @@ func (n *Notation) Render() string {
 	var system string
 
 	if n.PublicID != "" {
-		public = "\"" + n.PublicID + "\""
+		public = Quote(n.PublicID, n.PublicIDQuote)
 	}
 	if n.SystemID != "" {
-		system = " \"" + n.SystemID + "\""
+		system = " " + Quote(n.SystemID, n.SystemIDQuote)
 	}
 	return join("<!NOTATION ", n.Name, renderSystem(n.System), renderPublic(n.Public), public, system, ">")
 }
 
 // GetName Get the name
-// implements IDTDBlock
+// implements Named
 func (n *Notation) GetName() string {
 	return n.Name
 }
 
-// SetExported set the current entity to exported
-// implements IDTDBlock
-func (n *Notation) SetExported(v bool) {
-	panic("An Notation should never be set as exported")
-}
-
-// GetValue Get the value
-// implements IDTDBlock
-func (n *Notation) GetValue() string {
-	panic("An Notation has a PublicID or a SystemID")
-}
-
 // GetExtra Get extrainformation
 func (n *Notation) GetExtra() *DTDExtra {
 	var extra DTDExtra
@@ -60,6 +52,18 @@ func (n *Notation) GetExtra() *DTDExtra {
 	return &extra
 }
 
+// Kind returns NOTATION
+// implements IDTDBlock
+func (n *Notation) Kind() int {
+	return NOTATION
+}
+
+// Pos Get the position of the notation
+// implements IDTDBlock
+func (n *Notation) Pos() Pos {
+	return n.Position
+}
+
 // IsNotationType check if the interface is a DTD.Notation
 func IsNotationType(i interface{}) bool {
 	switch i.(type) {
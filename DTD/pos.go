@@ -0,0 +1,31 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+import "fmt"
+
+// Pos represents the location of a block or an attribute in a DTD file
+// Offset is in bytes from the beginning of the file once transcoded to UTF-8,
+// Line and Column start at 1 and Column counts characters. The end position is the one following the last character.
+type Pos struct {
+	File      string `json:"file"`
+	Offset    int    `json:"offset"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndOffset int    `json:"endOffset"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+}
+
+// String returns the position as file:line:column
+func (p Pos) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// IsValid tells if the position is set
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
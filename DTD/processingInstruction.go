@@ -0,0 +1,55 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+// ProcessingInstruction represents a processing instruction
+//
+// [16] PI       ::= '<?' PITarget (S (Char* - (Char* '?>' Char*)))? '?>'
+// [17] PITarget ::= Name - (('X' | 'x') ('M' | 'm') ('L' | 'l'))
+type ProcessingInstruction struct {
+	Target   string `json:"target"`
+	Value    string `json:"value,omitempty"`
+	Position Pos    `json:"position"`
+}
+
+// Render a processing instruction
+// implements IDTDBlock
+func (pi *ProcessingInstruction) Render() string {
+	if pi.Value == "" {
+		return join("<?", pi.Target, "?>")
+	}
+	return join("<?", pi.Target, " ", pi.Value, "?>")
+}
+
+// GetName Get the target
+// implements Named
+func (pi *ProcessingInstruction) GetName() string {
+	return pi.Target
+}
+
+// GetValue Get the instruction
+// implements Valued
+func (pi *ProcessingInstruction) GetValue() string {
+	return pi.Value
+}
+
+// GetExtra Get extrainformation
+func (pi *ProcessingInstruction) GetExtra() *DTDExtra {
+	var extra DTDExtra
+	return &extra
+}
+
+// Kind returns PI
+// implements IDTDBlock
+func (pi *ProcessingInstruction) Kind() int {
+	return PI
+}
+
+// Pos Get the position of the processing instruction
+// implements IDTDBlock
+func (pi *ProcessingInstruction) Pos() Pos {
+	return pi.Position
+}
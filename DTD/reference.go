@@ -0,0 +1,52 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+// Reference represents a parameter entity reference between declarations
+// @ref https://www.w3.org/TR/xml11/#NT-PEReference
+//
+// [69] PEReference ::= '%' Name ';'
+//
+// The declarations of the entity are included where the reference appears,
+// see Parser.Expand
+type Reference struct {
+	Name     string `json:"name"`
+	Position Pos    `json:"position"`
+}
+
+// Render a reference
+// implements IDTDBlock
+func (r *Reference) Render() string {
+	return join("%", r.Name, ";")
+}
+
+// GetName Get the name of the referenced entity
+// implements Named
+func (r *Reference) GetName() string {
+	return r.Name
+}
+
+// Kind returns REFERENCE
+// implements IDTDBlock
+func (r *Reference) Kind() int {
+	return REFERENCE
+}
+
+// Pos Get the position of the reference
+// implements IDTDBlock
+func (r *Reference) Pos() Pos {
+	return r.Position
+}
+
+// IsReferenceType check if the interface is a DTD.Reference
+func IsReferenceType(i interface{}) bool {
+	switch i.(type) {
+	case *Reference:
+		return true
+	default:
+		return false
+	}
+}
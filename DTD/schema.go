@@ -0,0 +1,125 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+// Schema represents a DTD and the external DTDs it includes
+//
+// Blocks holds the declarations of all the files in document order, parameter
+// entity references and conditional sections resolved. The maps index them by name,
+// the first declaration of an element, an entity or a notation is binding.
+// Attlists holds the ATTLIST declarations of each element in document order.
+// Root is the include tree, its modules hold the blocks as declared in each file.
+type Schema struct {
+	Root              *Module
+	Blocks            []IDTDBlock
+	Elements          map[string]*Element
+	Attlists          map[string][]*Attlist
+	Entities          map[string]*Entity
+	ParameterEntities map[string]*Entity
+	Notations         map[string]*Notation
+}
+
+// Module represents a DTD file and the external DTDs it references
+type Module struct {
+	File     string      `json:"file"`
+	Encoding string      `json:"encoding,omitempty"`
+	Blocks   []IDTDBlock `json:"blocks"`
+	Includes []*Include  `json:"includes,omitempty"`
+}
+
+// Include represents an external parameter entity and the module it references
+type Include struct {
+	Entity *Entity `json:"entity"`
+	Module *Module `json:"module"`
+}
+
+// NewSchema returns a new schema indexing blocks
+// blocks must be expanded, see Schema
+func NewSchema(root *Module, blocks []IDTDBlock) *Schema {
+	s := Schema{
+		Root:              root,
+		Blocks:            blocks,
+		Elements:          make(map[string]*Element),
+		Attlists:          make(map[string][]*Attlist),
+		Entities:          make(map[string]*Entity),
+		ParameterEntities: make(map[string]*Entity),
+		Notations:         make(map[string]*Notation),
+	}
+
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *Element:
+			if _, ok := s.Elements[b.Name]; !ok {
+				s.Elements[b.Name] = b
+			}
+		case *Attlist:
+			s.Attlists[b.Name] = append(s.Attlists[b.Name], b)
+		case *Entity:
+			table := s.Entities
+			if b.Parameter {
+				table = s.ParameterEntities
+			}
+			if _, ok := table[b.Name]; !ok {
+				table[b.Name] = b
+			}
+		case *Notation:
+			if _, ok := s.Notations[b.Name]; !ok {
+				s.Notations[b.Name] = b
+			}
+		}
+	}
+
+	return &s
+}
+
+// Attributes returns the attributes of an element declared in its attribute lists,
+// in document order
+// The first definition of an attribute is binding, the later ones are returned in duplicates.
+// References to undeclared parameter entities are skipped.
+func (s *Schema) Attributes(element string) (attributes []Attribute, duplicates []Attribute) {
+	defined := make(map[string]bool)
+
+	for _, attlist := range s.Attlists[element] {
+		for _, attr := range attlist.Attributes {
+			if attr.IsEntity {
+				continue
+			}
+
+			if defined[attr.Name] {
+				duplicates = append(duplicates, attr)
+				continue
+			}
+
+			defined[attr.Name] = true
+			attributes = append(attributes, attr)
+		}
+	}
+	return attributes, duplicates
+}
+
+// Modules returns the modules of the include tree, the root first
+// a module included several times is returned once
+func (s *Schema) Modules() []*Module {
+	var modules []*Module
+
+	seen := make(map[*Module]bool)
+
+	var walk func(m *Module)
+	walk = func(m *Module) {
+		if m == nil || seen[m] {
+			return
+		}
+		seen[m] = true
+		modules = append(modules, m)
+
+		for _, include := range m.Includes {
+			walk(include.Module)
+		}
+	}
+
+	walk(s.Root)
+	return modules
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTD Represents main structs of a DTD
+package DTD
+
+// Visitor is called by Walk for each block of a DTD and for the nodes they hold
+// The methods of the nodes having children return true to visit them:
+// the particles of an element, the attributes of an attribute list, the blocks of
+// a conditional section and the children of a group.
+//
+// Embed BaseVisitor to implement only the methods needed.
+type Visitor interface {
+	VisitXMLDecl(x *XMLDecl)
+	VisitProcessingInstruction(pi *ProcessingInstruction)
+	VisitComment(c *Comment)
+	VisitElement(e *Element) bool
+	VisitParticle(p *Particle) bool
+	VisitAttlist(a *Attlist) bool
+	VisitAttribute(a *Attribute)
+	VisitEntity(e *Entity)
+	VisitNotation(n *Notation)
+	VisitConditionalSection(c *ConditionalSection) bool
+	VisitReference(r *Reference)
+}
+
+// BaseVisitor is a Visitor doing nothing and visiting all the children
+type BaseVisitor struct{}
+
+// VisitXMLDecl implements Visitor
+func (BaseVisitor) VisitXMLDecl(x *XMLDecl) {}
+
+// VisitProcessingInstruction implements Visitor
+func (BaseVisitor) VisitProcessingInstruction(pi *ProcessingInstruction) {}
+
+// VisitComment implements Visitor
+func (BaseVisitor) VisitComment(c *Comment) {}
+
+// VisitElement implements Visitor
+func (BaseVisitor) VisitElement(e *Element) bool { return true }
+
+// VisitParticle implements Visitor
+func (BaseVisitor) VisitParticle(p *Particle) bool { return true }
+
+// VisitAttlist implements Visitor
+func (BaseVisitor) VisitAttlist(a *Attlist) bool { return true }
+
+// VisitAttribute implements Visitor
+func (BaseVisitor) VisitAttribute(a *Attribute) {}
+
+// VisitEntity implements Visitor
+func (BaseVisitor) VisitEntity(e *Entity) {}
+
+// VisitNotation implements Visitor
+func (BaseVisitor) VisitNotation(n *Notation) {}
+
+// VisitConditionalSection implements Visitor
+func (BaseVisitor) VisitConditionalSection(c *ConditionalSection) bool { return true }
+
+// VisitReference implements Visitor
+func (BaseVisitor) VisitReference(r *Reference) {}
+
+// Walk visits blocks in order, depth first
+// Blocks of unknown types are skipped.
+func Walk(v Visitor, blocks ...IDTDBlock) {
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *XMLDecl:
+			v.VisitXMLDecl(b)
+		case *ProcessingInstruction:
+			v.VisitProcessingInstruction(b)
+		case *Comment:
+			v.VisitComment(b)
+		case *Element:
+			if v.VisitElement(b) && b.Content != nil && b.Content.Root != nil {
+				walkParticle(v, b.Content.Root)
+			}
+		case *Attlist:
+			if v.VisitAttlist(b) {
+				for i := range b.Attributes {
+					v.VisitAttribute(&b.Attributes[i])
+				}
+			}
+		case *Entity:
+			v.VisitEntity(b)
+		case *Notation:
+			v.VisitNotation(b)
+		case *ConditionalSection:
+			if v.VisitConditionalSection(b) {
+				Walk(v, b.Blocks...)
+			}
+		case *Reference:
+			v.VisitReference(b)
+		}
+	}
+}
+
+// walkParticle visits a particle and its children
+func walkParticle(v Visitor, p *Particle) {
+	if !v.VisitParticle(p) {
+		return
+	}
+
+	for _, child := range p.Children {
+		walkParticle(v, child)
+	}
+}
@@ -5,50 +5,56 @@
 // Package DTD Represents main structs of a DTD
 package DTD
 
-// Attlist represents an attlist
+// XMLDecl represents a text declaration
+//
+// [77] TextDecl ::= '<?xml' VersionInfo? EncodingDecl S? '?>'
+//
+// External entities may start with a text declaration, a DTD may start
+// with an XML declaration, both are represented by XMLDecl.
 type XMLDecl struct {
-	Version  string
-	Encoding string
+	Version    string `json:"version,omitempty"`
+	Encoding   string `json:"encoding,omitempty"`
+	Standalone string `json:"standalone,omitempty"`
+	Position   Pos    `json:"position"`
 }
 
-// Render an Attlist
+// Render a text declaration
 // implements IDTDBlock
-func (a *XMLDecl) Render() string {
-	return join("")
+func (x *XMLDecl) Render() string {
+	return join("<?xml", x.GetValue(), "?>")
 }
 
-// GetName Get the name
-// implements IDTDBlock
-func (a *XMLDecl) GetName() string {
-	panic("A XML Declaration has no attribute name")
-}
+// GetValue Get the pseudo attributes
+// implements Valued
+func (x *XMLDecl) GetValue() string {
+	var s string
 
-// SetExported set the current entity to exported
-// implements IDTDBlock
-func (a *XMLDecl) SetExported(v bool) {
-	panic("A XML Declaration should never be set as exported")
+	if x.Version != "" {
+		s += " version=\"" + x.Version + "\""
+	}
+	if x.Encoding != "" {
+		s += " encoding=\"" + x.Encoding + "\""
+	}
+	if x.Standalone != "" {
+		s += " standalone=\"" + x.Standalone + "\""
+	}
+	return s
 }
 
-// GetValue Get the value
-// implements IDTDBlock
-func (a *XMLDecl) GetValue() string {
-	panic("A XML Declaration has no attribute value")
-}
-
-// GetParameter return parameter for entity only
-// implements IDTDBlock
-func (a *XMLDecl) GetParameter() bool {
-	panic("A XML Declaration has no attribute parameter")
+// GetExtra Get extrainformation
+func (x *XMLDecl) GetExtra() *DTDExtra {
+	var extra DTDExtra
+	return &extra
 }
 
-// GetUrl the entity url
+// Kind returns XMLDECL
 // implements IDTDBlock
-func (a *XMLDecl) GetUrl() string {
-	panic("A XML Declaration has no attribute URL")
+func (x *XMLDecl) Kind() int {
+	return XMLDECL
 }
 
-// GetExported Unused, tells if the comment was exported
+// Pos Get the position of the declaration
 // implements IDTDBlock
-func (a *XMLDecl) GetExported() bool {
-	panic("A XML Declaration can'T be exported")
+func (x *XMLDecl) Pos() Pos {
+	return x.Position
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/blefort/DTDParser/DTD"
@@ -25,29 +27,25 @@ func loadAttlistTests(file string) []AttrTestResult {
 func TestParseAttlistBlock(t *testing.T) {
 	// - parse the DTD test
 	// - compare it to data stored in a json file
-	// - render it in the tmp dir
-	testAttlistDTD(t, "tests/attlist.dtd", true)
+	// - render it in a temporary dir
+	tmp := t.TempDir()
+	testAttlistDTD(t, "tests/attlist.dtd", tmp)
 
 	// - load the generated DTD
 	// - compare it to data stored in a json file
-	//	testAttlistDTD(t, "tmp/attlist.dtd", false)
+	testAttlistDTD(t, filepath.Join(tmp, "attlist.dtd"), t.TempDir())
 }
 
 // testAttlistDTD main testing func for attlist
-func testAttlistDTD(t *testing.T, path string, recreate bool) {
+func testAttlistDTD(t *testing.T, path string, dir string) {
 	var tests []AttrTestResult
-	var dir string
-
-	if recreate {
-		dir = "tmp"
-	} else {
-		dir = "tmp2"
-	}
 
 	// New parser
 	p := newParser(dir)
 
-	p.Parse(path)
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
 	tests = loadAttlistTests("tests/attlist.json")
 
 	if len(p.Collection) != len(tests) {
@@ -114,9 +112,42 @@ func testAttlistDTD(t *testing.T, path string, recreate bool) {
 	t.Run("Render DTD", render(p))
 }
 
-// AttlistExported() Helper to test DTD.Attlist.GetExported()
-func AttlistExported() {
-	var a DTD.Attlist
-	ret := a.GetExported()
-	log.Debugf("AttlistExported( return %t", ret)
+// TestAttributeDefaults Test the enumeration and the default declaration of attributes
+func TestAttributeDefaults(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	src := "<!ATTLIST doc\n" +
+		"  status (draft | final) 'draft'\n" +
+		"  format NOTATION (gif|png) #IMPLIED\n" +
+		"  version CDATA #FIXED \"1.0\"\n" +
+		"  id ID #REQUIRED>"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	attlist := p.Collection[0].(*DTD.Attlist)
+
+	if len(attlist.Attributes) != 4 {
+		t.Fatalf("Number of attributes (%d) differs from 4", len(attlist.Attributes))
+	}
+
+	status := attlist.Attributes[0]
+	t.Run("Check enumeration", checkStrValue(strings.Join(status.Enumeration, ","), "draft,final", status, nil))
+	t.Run("Check default kind", checkIntValue(status.DefaultKind, DTD.DEFAULT_VALUE, status, nil))
+	t.Run("Check default value", checkStrValue(status.DefaultValue, "draft", status, nil))
+	t.Run("Check render", checkStrValue(status.Render(), "\tstatus\t(draft|final) 'draft'\n", status, nil))
+
+	format := attlist.Attributes[1]
+	t.Run("Check notation enumeration", checkStrValue(strings.Join(format.Enumeration, ","), "gif,png", format, nil))
+	t.Run("Check implied", checkIntValue(format.DefaultKind, DTD.DEFAULT_IMPLIED, format, nil))
+	t.Run("Check notation render", checkStrValue(format.Render(), "\tformat\tNOTATION (gif|png) #IMPLIED\n", format, nil))
+
+	version := attlist.Attributes[2]
+	t.Run("Check fixed", checkIntValue(version.DefaultKind, DTD.DEFAULT_FIXED, version, nil))
+	t.Run("Check fixed value", checkStrValue(version.DefaultValue, "1.0", version, nil))
+
+	id := attlist.Attributes[3]
+	t.Run("Check required", checkIntValue(id.DefaultKind, DTD.DEFAULT_REQUIRED, id, nil))
+	t.Run("Check no enumeration", checkIntValue(len(id.Enumeration), 0, id, nil))
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// TestAttributeDefinitions Test the ATTLIST declarations of an element are merged
+func TestAttributeDefinitions(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	if err := p.Parse("tests/tobeadded.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	attributes, warnings, err := p.AttributeDefinitions("student_name")
+
+	if err != nil {
+		t.Fatalf("Merging attributes failed: %v", err)
+	}
+
+	if len(attributes) != 3 {
+		t.Fatalf("Number of attributes (%d) differs from 3", len(attributes))
+	}
+
+	names := []string{"student_no", "tutor_1", "tutor_2"}
+
+	for i, name := range names {
+		t.Run("Check name", checkStrValue(attributes[i].Name, name, attributes[i], nil))
+	}
+
+	// first definition wins
+	t.Run("Check type", checkStrValue(attributes[0].RenderType(), "ID", attributes[0], nil))
+
+	if len(warnings) != 2 {
+		t.Fatalf("Number of warnings (%d) differs from 2", len(warnings))
+	}
+
+	t.Run("Check warning attribute", checkStrValue(warnings[0].Attribute, "student_no", warnings[0], nil))
+	t.Run("Check warning line", checkIntValue(warnings[0].Position.Line, 5, warnings[0], nil))
+	t.Run("Check first line", checkIntValue(warnings[0].First.Line, 4, warnings[0], nil))
+	t.Run("Check last warning line", checkIntValue(warnings[1].Position.Line, 18, warnings[1], nil))
+}
+
+// TestExternalAttributeDefinitions Test the ATTLIST declarations of external modules are merged
+func TestExternalAttributeDefinitions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.dtd": {Data: []byte("<!ATTLIST doc id ID #REQUIRED>\n" +
+			"<!ENTITY % common \"lang CDATA #IMPLIED\">\n" +
+			"<!ENTITY % mod SYSTEM \"mod.ent\">\n" +
+			"%mod;\n" +
+			"<!ATTLIST doc %common; role CDATA #IMPLIED>\n")},
+		"mod.ent": {Data: []byte("<!ATTLIST doc id CDATA #IMPLIED>\n" +
+			"<![INCLUDE[<!ATTLIST doc status (draft|final) \"draft\">]]>\n" +
+			"<![IGNORE[<!ATTLIST doc ignored CDATA #IMPLIED>]]>\n")},
+	}
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	if err := p.Parse("main.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	attributes, warnings, err := p.AttributeDefinitions("doc")
+
+	if err != nil {
+		t.Fatalf("Merging attributes failed: %v", err)
+	}
+
+	names := []string{"id", "status", "lang", "role"}
+
+	if len(attributes) != len(names) {
+		t.Fatalf("Number of attributes (%d) differs from %d", len(attributes), len(names))
+	}
+
+	for i, name := range names {
+		t.Run("Check name", checkStrValue(attributes[i].Name, name, attributes[i], nil))
+	}
+
+	t.Run("Check first definition", checkStrValue(attributes[0].RenderType(), "ID", attributes[0], nil))
+
+	if len(warnings) != 1 {
+		t.Fatalf("Number of warnings (%d) differs from 1", len(warnings))
+	}
+
+	t.Run("Check warning file", checkStrValue(warnings[0].Position.File, "mod.ent", warnings[0], nil))
+	t.Run("Check first file", checkStrValue(warnings[0].First.File, "main.dtd", warnings[0], nil))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.uber.org/zap"
+
+	DTDParser "github.com/blefort/DTDParser/parser"
+	"github.com/blefort/DTDParser/scanner"
+)
+
+// benchmarkModule returns a DocBook like module declaring n elements
+func benchmarkModule(prefix string, n int) string {
+	var sb strings.Builder
+
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	sb.WriteString("<!ENTITY % " + prefix + ".common.attributes \"id ID #IMPLIED role CDATA #IMPLIED\">\n")
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("%s.element%d", prefix, i)
+		fmt.Fprintf(&sb, "<!-- ================ %s ================ -->\n", name)
+		fmt.Fprintf(&sb, "<!ENTITY %% %s.module \"INCLUDE\">\n", name)
+		fmt.Fprintf(&sb, "<![%%%s.module;[\n", name)
+		fmt.Fprintf(&sb, "<!ELEMENT %s (title, (para | list | %s.inline)*, note?)>\n", name, prefix)
+		fmt.Fprintf(&sb, "<!ATTLIST %s\n\t%%%s.common.attributes;\n\tstatus (draft|final) \"draft\"\n\tlang CDATA #IMPLIED>\n", name, prefix)
+		sb.WriteString("]]>\n")
+		fmt.Fprintf(&sb, "<!ENTITY %s.label \"Label of %s, &#xA9; 2019\">\n", name, name)
+	}
+
+	return sb.String()
+}
+
+// BenchmarkScanner Benchmark the scanner on a multi-megabyte DTD
+func BenchmarkScanner(b *testing.B) {
+	dtd := benchmarkModule("db", 10000)
+	nop := zap.NewNop().Sugar()
+
+	b.SetBytes(int64(len(dtd)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sc := scanner.NewReaderScanner("bench.dtd", strings.NewReader(dtd), nop)
+
+		for sc.NextBlock() {
+			if _, err := sc.Scan(); err != nil {
+				b.Fatalf("Scanning failed: %v", err)
+			}
+		}
+	}
+}
+
+// BenchmarkParseFS Benchmark the parser on a set of DTD modules
+func BenchmarkParseFS(b *testing.B) {
+	var main strings.Builder
+	var size int
+
+	fsys := fstest.MapFS{}
+
+	for m := 0; m < 8; m++ {
+		name := fmt.Sprintf("module%d", m)
+		module := benchmarkModule(name, 1500)
+		fsys[name+".mod"] = &fstest.MapFile{Data: []byte(module)}
+		fmt.Fprintf(&main, "<!ENTITY %% %s SYSTEM \"%s.mod\">\n%%%s;\n", name, name, name)
+		size += len(module)
+	}
+
+	fsys["main.dtd"] = &fstest.MapFile{Data: []byte(main.String())}
+	size += main.Len()
+
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := DTDParser.NewDTDParser(zap.NewNop().Sugar())
+		p.SetFS(fsys)
+
+		if err := p.Parse("main.dtd"); err != nil {
+			b.Fatalf("Parsing failed: %v", err)
+		}
+	}
+}
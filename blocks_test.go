@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// TestBlockCapabilities Test that every kind of block can be used through its interfaces
+func TestBlockCapabilities(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	src := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<?xml-stylesheet href=\"style.xsl\"?>\n" +
+		"<!-- a comment -->\n" +
+		"<!ELEMENT doc (#PCDATA)>\n" +
+		"<!ATTLIST doc id ID #IMPLIED>\n" +
+		"<!ENTITY % local \"INCLUDE\">\n" +
+		"<!NOTATION gif SYSTEM \"image/gif\">\n" +
+		"<![%local;[\n<!ELEMENT note EMPTY>\n]]>"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	kinds := []int{DTD.XMLDECL, DTD.PI, DTD.COMMENT, DTD.ELEMENT, DTD.ATTLIST, DTD.ENTITY, DTD.NOTATION, DTD.CONDITIONAL}
+
+	if len(p.Collection) != len(kinds) {
+		t.Fatalf("Number of blocks (%d) differs from %d", len(p.Collection), len(kinds))
+	}
+
+	for i, block := range p.Collection {
+		t.Run("Check kind", checkIntValue(block.Kind(), kinds[i], block, nil))
+		t.Run("Check position", checkBoolValue(block.Pos().IsValid(), true, block, nil))
+		t.Run("Check render", checkBoolValue(block.Render() != "", true, block, nil))
+
+		if n, ok := block.(DTD.Named); ok {
+			n.GetName()
+		}
+		if v, ok := block.(DTD.Valued); ok {
+			v.GetValue()
+		}
+		if e, ok := block.(DTD.Exportable); ok {
+			e.SetExported(true)
+			t.Run("Check exported", checkBoolValue(e.IsExported(), true, block, nil))
+		}
+	}
+
+	_, named := p.Collection[2].(DTD.Named)
+	t.Run("Check comment has no name", checkBoolValue(named, false, p.Collection[2], nil))
+
+	_, exportable := p.Collection[3].(DTD.Exportable)
+	t.Run("Check element is not exportable", checkBoolValue(exportable, false, p.Collection[3], nil))
+}
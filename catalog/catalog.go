@@ -0,0 +1,399 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package catalog resolves public and system identifiers with OASIS XML Catalogs
+//
+// Specifications: https://www.oasis-open.org/committees/download.php/14809/xml-catalogs.html
+//
+// # This is a simplified implementation
+//
+// Supported entries are public, system, rewriteSystem, systemSuffix, delegatePublic,
+// delegateSystem, uri, rewriteURI, uriSuffix, delegateURI and nextCatalog,
+// grouped or not, with xml:base and prefer attributes.
+// Catalogs referenced by nextCatalog and delegate entries are loaded when needed,
+// missing ones are ignored as recommended by the specification.
+package catalog
+
+import (
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// Entry represents a catalog entry
+// Type is the name of the entry element, Match the identifier, the prefix
+// or the suffix to match and Value the URI, the rewrite prefix or the catalog
+// to use, resolved against xml:base
+type Entry struct {
+	Type   string
+	Match  string
+	Value  string
+	Prefer string
+}
+
+// Catalog represents an OASIS XML Catalog
+type Catalog struct {
+	Path    string
+	Entries []Entry
+	fsys    fs.FS
+	loaded  map[string]*Catalog
+}
+
+// frame holds the inherited attributes of catalog and group elements
+type frame struct {
+	base   string
+	prefer string
+}
+
+// Load load a catalog from the local file system
+func Load(name string) (*Catalog, error) {
+	return LoadFS(nil, name)
+}
+
+// LoadFS load a catalog from fsys, or from the local file system when fsys is nil
+// catalogs it references are loaded from the same file system
+func LoadFS(fsys fs.FS, name string) (*Catalog, error) {
+	var f io.ReadCloser
+	var err error
+
+	if fsys != nil {
+		f, err = fsys.Open(name)
+	} else {
+		f, err = os.Open(name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c, err := Parse(name, f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.fsys = fsys
+	return c, nil
+}
+
+// Parse read a catalog from r
+// name is the location of the catalog, relative URIs are resolved against it
+func Parse(name string, r io.Reader) (*Catalog, error) {
+	c := Catalog{Path: name}
+	stack := []frame{{base: name, prefer: "public"}}
+
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			current := stack[len(stack)-1]
+
+			if base := attr(t, "base", xmlNamespace); base != "" {
+				current.base = resolve(current.base, base)
+			}
+
+			if prefer := attr(t, "prefer", ""); prefer != "" {
+				current.prefer = prefer
+			}
+
+			stack = append(stack, current)
+			c.addEntry(t, current)
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return &c, nil
+}
+
+// addEntry add the entry represented by the element e
+func (c *Catalog) addEntry(e xml.StartElement, f frame) {
+	var match, value string
+
+	switch e.Name.Local {
+	case "public":
+		match, value = normalizePublicID(attr(e, "publicId", "")), attr(e, "uri", "")
+	case "system":
+		match, value = attr(e, "systemId", ""), attr(e, "uri", "")
+	case "rewriteSystem":
+		match, value = attr(e, "systemIdStartString", ""), attr(e, "rewritePrefix", "")
+	case "systemSuffix":
+		match, value = attr(e, "systemIdSuffix", ""), attr(e, "uri", "")
+	case "delegatePublic":
+		match, value = normalizePublicID(attr(e, "publicIdStartString", "")), attr(e, "catalog", "")
+	case "delegateSystem":
+		match, value = attr(e, "systemIdStartString", ""), attr(e, "catalog", "")
+	case "uri":
+		match, value = attr(e, "name", ""), attr(e, "uri", "")
+	case "rewriteURI":
+		match, value = attr(e, "uriStartString", ""), attr(e, "rewritePrefix", "")
+	case "uriSuffix":
+		match, value = attr(e, "uriSuffix", ""), attr(e, "uri", "")
+	case "delegateURI":
+		match, value = attr(e, "uriStartString", ""), attr(e, "catalog", "")
+	case "nextCatalog":
+		value = attr(e, "catalog", "")
+	default:
+		return
+	}
+
+	if value == "" {
+		return
+	}
+
+	c.Entries = append(c.Entries, Entry{
+		Type:   e.Name.Local,
+		Match:  match,
+		Value:  resolve(f.base, value),
+		Prefer: f.prefer,
+	})
+}
+
+// Resolve resolve an external identifier
+// It returns an empty string if the catalog has no matching entry
+func (c *Catalog) Resolve(publicID string, systemID string) string {
+	return c.resolveExternal(normalizePublicID(publicID), systemID, map[string]bool{})
+}
+
+// ResolveURI resolve a URI reference
+// It returns an empty string if the catalog has no matching entry
+func (c *Catalog) ResolveURI(uri string) string {
+	return c.resolveURI(uri, map[string]bool{})
+}
+
+// resolveExternal follows section 7.1.2 of the specification
+func (c *Catalog) resolveExternal(publicID string, systemID string, visited map[string]bool) string {
+
+	if visited[c.Path] {
+		return ""
+	}
+	visited[c.Path] = true
+
+	if systemID != "" {
+		if uri, ok := c.match("system", "rewriteSystem", "systemSuffix", systemID); ok {
+			return uri
+		}
+
+		if delegates, ok := c.delegates("delegateSystem", systemID); ok {
+			for _, d := range delegates {
+				if uri := d.resolveExternal("", systemID, visited); uri != "" {
+					return uri
+				}
+			}
+			return ""
+		}
+	}
+
+	if publicID != "" {
+		for _, e := range c.Entries {
+			if e.Type == "public" && e.Match == publicID && (systemID == "" || e.Prefer == "public") {
+				return e.Value
+			}
+		}
+
+		if delegates, ok := c.delegates("delegatePublic", publicID); ok {
+			for _, d := range delegates {
+				if uri := d.resolveExternal(publicID, "", visited); uri != "" {
+					return uri
+				}
+			}
+			return ""
+		}
+	}
+
+	for _, next := range c.nextCatalogs() {
+		if uri := next.resolveExternal(publicID, systemID, visited); uri != "" {
+			return uri
+		}
+	}
+
+	return ""
+}
+
+// resolveURI follows section 7.2.2 of the specification
+func (c *Catalog) resolveURI(uri string, visited map[string]bool) string {
+
+	if visited[c.Path] {
+		return ""
+	}
+	visited[c.Path] = true
+
+	if resolved, ok := c.match("uri", "rewriteURI", "uriSuffix", uri); ok {
+		return resolved
+	}
+
+	if delegates, ok := c.delegates("delegateURI", uri); ok {
+		for _, d := range delegates {
+			if resolved := d.resolveURI(uri, visited); resolved != "" {
+				return resolved
+			}
+		}
+		return ""
+	}
+
+	for _, next := range c.nextCatalogs() {
+		if resolved := next.resolveURI(uri, visited); resolved != "" {
+			return resolved
+		}
+	}
+
+	return ""
+}
+
+// match look for an exact match, then the longest rewrite prefix, then the longest suffix
+func (c *Catalog) match(exact string, rewrite string, suffix string, id string) (string, bool) {
+	var rewriteEntry, suffixEntry *Entry
+
+	for i, e := range c.Entries {
+		switch {
+		case e.Type == exact && e.Match == id:
+			return e.Value, true
+		case e.Type == rewrite && strings.HasPrefix(id, e.Match):
+			if rewriteEntry == nil || len(e.Match) > len(rewriteEntry.Match) {
+				rewriteEntry = &c.Entries[i]
+			}
+		case e.Type == suffix && strings.HasSuffix(id, e.Match):
+			if suffixEntry == nil || len(e.Match) > len(suffixEntry.Match) {
+				suffixEntry = &c.Entries[i]
+			}
+		}
+	}
+
+	if rewriteEntry != nil {
+		return rewriteEntry.Value + strings.TrimPrefix(id, rewriteEntry.Match), true
+	}
+
+	if suffixEntry != nil {
+		return suffixEntry.Value, true
+	}
+
+	return "", false
+}
+
+// delegates returns the catalogs of the delegate entries matching id, longest prefix first
+// ok is false when no entry matches, delegation happens even if no catalog could be loaded
+func (c *Catalog) delegates(entryType string, id string) ([]*Catalog, bool) {
+	var entries []Entry
+	var catalogs []*Catalog
+
+	for _, e := range c.Entries {
+		if e.Type == entryType && strings.HasPrefix(id, e.Match) {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(entries[i].Match) > len(entries[j].Match)
+	})
+
+	for _, e := range entries {
+		if d := c.load(e.Value); d != nil {
+			catalogs = append(catalogs, d)
+		}
+	}
+
+	return catalogs, len(entries) > 0
+}
+
+// nextCatalogs returns the catalogs of the nextCatalog entries
+func (c *Catalog) nextCatalogs() []*Catalog {
+	var catalogs []*Catalog
+
+	for _, e := range c.Entries {
+		if e.Type != "nextCatalog" {
+			continue
+		}
+		if next := c.load(e.Value); next != nil {
+			catalogs = append(catalogs, next)
+		}
+	}
+
+	return catalogs
+}
+
+// load load a referenced catalog once, nil is returned if it can't be loaded
+func (c *Catalog) load(name string) *Catalog {
+
+	if c.loaded == nil {
+		c.loaded = make(map[string]*Catalog)
+	}
+
+	if loaded, ok := c.loaded[name]; ok {
+		return loaded
+	}
+
+	loaded, err := LoadFS(c.fsys, strings.TrimPrefix(name, "file://"))
+
+	if err != nil {
+		loaded = nil
+	}
+
+	c.loaded[name] = loaded
+	return loaded
+}
+
+// attr returns the value of an attribute
+func attr(e xml.StartElement, local string, space string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local && a.Name.Space == space {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// normalizePublicID normalize white spaces of a public ID
+func normalizePublicID(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// resolve resolve ref against base
+func resolve(base string, ref string) string {
+
+	if isAbsolute(ref) {
+		return ref
+	}
+
+	base = strings.TrimPrefix(base, "file://")
+	dir := base
+	if !strings.HasSuffix(base, "/") {
+		dir = path.Dir(base)
+	}
+
+	resolved := path.Join(dir, ref)
+
+	if strings.HasSuffix(ref, "/") {
+		resolved += "/"
+	}
+
+	return resolved
+}
+
+// isAbsolute tells if ref is an absolute path or has a URI scheme
+func isAbsolute(ref string) bool {
+	if path.IsAbs(ref) {
+		return true
+	}
+	i := strings.Index(ref, ":")
+	return i > 1 && !strings.ContainsAny(ref[:i], "/\\")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/blefort/DTDParser/catalog"
+)
+
+// TestCatalogResolve Test resolution of external identifiers with a catalog
+func TestCatalogResolve(t *testing.T) {
+
+	c, err := catalog.Load("tests/catalog.xml")
+
+	if err != nil {
+		t.Fatalf("Loading catalog failed: %v", err)
+	}
+
+	tests := []struct {
+		publicID string
+		systemID string
+		expected string
+	}{
+		{"-//OASIS//ENTITIES DITA 1.2 Concept//EN", "concept.ent", "tests/external.ent"},
+		{"-//OASIS//ENTITIES  DITA 1.2 Concept//EN", "", "tests/external.ent"},
+		{"", "http://example.com/dtd/topic.ent", "tests/dita/topic.ent"},
+		{"", "http://example.com/rewrite/mods/a.mod", "tests/mods/a.mod"},
+		{"-//ACME//ENTITIES Widgets//EN", "widgets.ent", "tests/external2.ent"},
+		{"-//ACME//ENTITIES Unknown//EN", "", ""},
+		{"", "next.ent", "tests/external.ent"},
+		{"", "unknown.ent", ""},
+	}
+
+	for _, test := range tests {
+		t.Run("Check resolved "+test.publicID+test.systemID, checkStrValue(c.Resolve(test.publicID, test.systemID), test.expected, test, nil))
+	}
+
+	t.Run("Check URI", checkStrValue(c.ResolveURI("urn:example:external2"), "tests/external2.ent", c, nil))
+}
+
+// TestParseWithCatalog Test external entities are resolved with a catalog
+func TestParseWithCatalog(t *testing.T) {
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+
+	if err := p.AddCatalog("tests/catalog.xml"); err != nil {
+		t.Fatalf("Loading catalog failed: %v", err)
+	}
+
+	// concept.ent does not exist, the catalog redirects it to external.ent
+	if err := p.Parse("tests/entity.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	schema, err := p.Schema()
+
+	if err != nil {
+		t.Fatalf("Expanding failed: %v", err)
+	}
+
+	file := ""
+	for _, include := range schema.Root.Includes {
+		if include.Entity.Name == "concept-dec" {
+			file = include.Module.File
+		}
+	}
+
+	t.Run("Check redirected file", checkStrValue(file, "tests/external.ent", schema.Root, nil))
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package charset detects the encoding of a DTD and transcodes it to UTF-8
+//
+// Specifications: https://www.w3.org/TR/xml11/#sec-guessing
+//
+// # This is a simplified implementation
+//
+// The encoding is detected with the byte order mark, then with the first
+// characters of the text declaration and its encoding pseudo attribute.
+// Supported encodings are UTF-8, UTF-16, ISO-8859-1, US-ASCII and windows-1252.
+package charset
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Supported encodings
+const (
+	UTF8        = "UTF-8"
+	UTF16       = "UTF-16"
+	UTF16BE     = "UTF-16BE"
+	UTF16LE     = "UTF-16LE"
+	Latin1      = "ISO-8859-1"
+	ASCII       = "US-ASCII"
+	Windows1252 = "windows-1252"
+)
+
+// encodingDecl matches the encoding pseudo attribute of a text declaration
+var encodingDecl = regexp.MustCompile(`^<\?xml\s[^>]*?encoding\s*=\s*["']([A-Za-z][A-Za-z0-9._-]*)["']`)
+
+// aliases maps lower case encoding names to supported encodings
+var aliases = map[string]string{
+	"utf-8":        UTF8,
+	"utf8":         UTF8,
+	"utf-16":       UTF16,
+	"utf16":        UTF16,
+	"utf-16be":     UTF16BE,
+	"utf-16le":     UTF16LE,
+	"iso-8859-1":   Latin1,
+	"iso_8859-1":   Latin1,
+	"iso8859-1":    Latin1,
+	"latin1":       Latin1,
+	"l1":           Latin1,
+	"us-ascii":     ASCII,
+	"ascii":        ASCII,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+}
+
+// windows1252 maps the bytes 0x80 to 0x9F, 0 are undefined
+var windows1252 = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+// UnsupportedError represents an encoding that can't be decoded
+type UnsupportedError struct {
+	Encoding string
+}
+
+// Error implements error
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("encoding '%s' is not supported", e.Encoding)
+}
+
+// DecodeError represents a byte sequence that is invalid in the encoding
+type DecodeError struct {
+	Encoding string
+	Offset   int
+}
+
+// Error implements error
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("invalid %s byte sequence at offset %d", e.Encoding, e.Offset)
+}
+
+// Canonical returns the name of a supported encoding
+// names are case insensitive, common aliases are accepted
+func Canonical(name string) (string, error) {
+	if enc, ok := aliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return enc, nil
+	}
+	return "", &UnsupportedError{Encoding: name}
+}
+
+// Detect returns the encoding of data and the length of its byte order mark
+// UTF-16 is detected with the first bytes, otherwise the encoding declared
+// in the text declaration is returned as is, or UTF-8 if none is declared.
+func Detect(data []byte) (string, int) {
+	enc, bom := sniff(data)
+
+	if enc != "" && enc != UTF8 {
+		return enc, bom
+	}
+
+	if m := encodingDecl.FindSubmatch(data[bom:]); m != nil {
+		return string(m[1]), bom
+	}
+
+	return UTF8, bom
+}
+
+// Decode transcodes data to UTF-8
+// forced, when not empty, overrides the detected encoding.
+// It returns the text, without byte order mark, and the encoding used.
+func Decode(data []byte, forced string) (string, string, error) {
+	sniffed, bom := sniff(data)
+
+	if forced != "" {
+		enc, err := Canonical(forced)
+
+		if err != nil {
+			return "", "", err
+		}
+
+		// the byte order of UTF-16 is given by the byte order mark
+		if enc == UTF16 && strings.HasPrefix(sniffed, UTF16) {
+			enc = sniffed
+		}
+
+		// a byte order mark is decoded when it doesn't match the forced encoding
+		if sniffed != enc {
+			bom = 0
+		}
+
+		return decode(data, bom, enc)
+	}
+
+	declared, _ := Detect(data)
+	enc, err := Canonical(declared)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if strings.HasPrefix(sniffed, UTF16) {
+		// the declaration of an UTF-16 document must agree with its byte order
+		if enc != UTF16 && enc != sniffed {
+			text, _, _ := decode(data, bom, sniffed)
+			if m := encodingDecl.FindStringSubmatch(text); m != nil {
+				return "", "", fmt.Errorf("encoding '%s' declared in a %s document", m[1], sniffed)
+			}
+		}
+		return decode(data, bom, sniffed)
+	}
+
+	if strings.HasPrefix(enc, UTF16) {
+		return "", "", fmt.Errorf("encoding '%s' declared in a document without UTF-16 byte order mark", declared)
+	}
+
+	if sniffed == UTF8 && enc != UTF8 {
+		return "", "", fmt.Errorf("encoding '%s' declared in a document with UTF-8 byte order mark", declared)
+	}
+
+	return decode(data, bom, enc)
+}
+
+// sniff detects the encoding with the byte order mark and the first characters
+// It returns an empty encoding when data starts with an ASCII character.
+func sniff(data []byte) (string, int) {
+	switch {
+	case hasPrefix(data, 0xEF, 0xBB, 0xBF):
+		return UTF8, 3
+	case hasPrefix(data, 0xFE, 0xFF):
+		return UTF16BE, 2
+	case hasPrefix(data, 0xFF, 0xFE):
+		return UTF16LE, 2
+	case hasPrefix(data, 0x00, '<', 0x00, '?'):
+		return UTF16BE, 0
+	case hasPrefix(data, '<', 0x00, '?', 0x00):
+		return UTF16LE, 0
+	}
+	return "", 0
+}
+
+// decode transcodes data after the byte order mark
+func decode(data []byte, bom int, enc string) (string, string, error) {
+	data = data[bom:]
+
+	switch enc {
+	case UTF8:
+		if !utf8.Valid(data) {
+			return "", "", &DecodeError{Encoding: enc, Offset: bom + invalidUTF8(data)}
+		}
+		return string(data), enc, nil
+
+	case UTF16, UTF16BE, UTF16LE:
+		if enc == UTF16 {
+			enc = UTF16BE
+		}
+		if len(data)%2 != 0 {
+			return "", "", &DecodeError{Encoding: enc, Offset: bom + len(data) - 1}
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if enc == UTF16BE {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			} else {
+				units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+			}
+		}
+		return string(utf16.Decode(units)), enc, nil
+
+	case Latin1, ASCII, Windows1252:
+		var sb strings.Builder
+		sb.Grow(len(data))
+		for i, b := range data {
+			r := rune(b)
+			if enc == ASCII && b > 0x7F {
+				return "", "", &DecodeError{Encoding: enc, Offset: bom + i}
+			}
+			if enc == Windows1252 && b >= 0x80 && b <= 0x9F {
+				r = windows1252[b-0x80]
+				if r == 0 {
+					return "", "", &DecodeError{Encoding: enc, Offset: bom + i}
+				}
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String(), enc, nil
+	}
+
+	return "", "", &UnsupportedError{Encoding: enc}
+}
+
+// invalidUTF8 returns the offset of the first invalid UTF-8 sequence
+func invalidUTF8(data []byte) int {
+	offset := 0
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return offset
+		}
+		offset += size
+		data = data[size:]
+	}
+	return offset
+}
+
+// hasPrefix tells if data starts with prefix
+func hasPrefix(data []byte, prefix ...byte) bool {
+	if len(data) < len(prefix) {
+		return false
+	}
+	for i, b := range prefix {
+		if data[i] != b {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
 )
 
 // CommentTestResult struct to test comment
@@ -22,31 +25,27 @@ func loadCommentTests(file string) []CommentTestResult {
 func TestParseCommentBlock(t *testing.T) {
 	// - parse the DTD test
 	// - compare it to data stored in a json file
-	// - render it in the tmp dir
+	// - render it in a temporary dir
 	t.Log("Start tests on 'tests/comment.dtd'")
-	testCommentDTD(t, "tests/comment.dtd", true)
+	tmp := t.TempDir()
+	testCommentDTD(t, "tests/comment.dtd", tmp)
 
 	// - load the generated DTD
 	// - compare it to data stored in a json file
-	t.Log("Start tests on 'tmp/comment.dtd'")
-	testCommentDTD(t, "tmp/comment.dtd", false)
+	t.Log("Start tests on the rendered 'comment.dtd'")
+	testCommentDTD(t, filepath.Join(tmp, "comment.dtd"), t.TempDir())
 }
 
 // testCommentDTD Main func holding tests
-func testCommentDTD(t *testing.T, path string, recreate bool) {
+func testCommentDTD(t *testing.T, path string, dir string) {
 	var tests []CommentTestResult
-	var dir string
-
-	if recreate {
-		dir = "tmp"
-	} else {
-		dir = "tmp2"
-	}
 
 	// New parser
 	p := newParser(dir)
 
-	p.Parse(path)
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
 	tests = loadCommentTests("tests/comment.json")
 
 	if len(p.Collection) != len(tests) {
@@ -56,10 +55,14 @@ func testCommentDTD(t *testing.T, path string, recreate bool) {
 
 	for idx, test := range tests {
 
-		parsedBlock := p.Collection[idx]
+		parsedBlock, ok := p.Collection[idx].(*DTD.Comment)
+
+		if !ok {
+			t.Errorf("Block %d is not a comment: %s", idx, DTD.Translate(p.Collection[idx].Kind()))
+			continue
+		}
 
-		t.Run("Check name", checkStrValue(parsedBlock.GetName(), test.Name, parsedBlock, test))
-		t.Run("Check value", checkStrValue(parsedBlock.GetValue(), test.Value, parsedBlock, test))
+		t.Run("Check value", checkStrValue(parsedBlock.Value, test.Value, parsedBlock, test))
 	}
 
 	t.Run("Render DTD", render(p))
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// TestParseConditionalBlock Test parser for conditional sections
+func TestParseConditionalBlock(t *testing.T) {
+	// - parse the DTD test
+	// - render it in a temporary dir
+	tmp := t.TempDir()
+	testConditionalDTD(t, "tests/conditional.dtd", tmp)
+
+	// - load the generated DTD
+	testConditionalDTD(t, filepath.Join(tmp, "conditional.dtd"), t.TempDir())
+}
+
+// testConditionalDTD Main func holding tests
+func testConditionalDTD(t *testing.T, path string, dir string) {
+
+	// New parser
+	p := newParser(dir)
+
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
+
+	if len(p.Collection) != 3 {
+		t.Fatalf("Number of blocks in the collection (%d) differs from 3", len(p.Collection))
+	}
+
+	section, ok := p.Collection[1].(*DTD.ConditionalSection)
+
+	if !ok {
+		t.Fatalf("Expected a conditional section, got %#v", p.Collection[1])
+	}
+
+	t.Run("Check keyword", checkStrValue(section.Keyword, "%local.mode;", section, nil))
+	t.Run("Check entity name", checkStrValue(section.EntityName(), "local.mode", section, nil))
+	t.Run("Check blocks", checkIntValue(len(section.Blocks), 3, section, nil))
+
+	included, err := section.Included(func(name string) (string, bool) { return "INCLUDE", name == "local.mode" })
+	t.Run("Check included", checkBoolValue(included && err == nil, true, section, err))
+
+	nested := section.Blocks[2].(*DTD.ConditionalSection)
+	t.Run("Check nested keyword", checkStrValue(nested.Keyword, "IGNORE", nested, nil))
+	t.Run("Check nested blocks", checkIntValue(len(nested.Blocks), 0, nested, nil))
+
+	included, err = nested.Included(nil)
+	t.Run("Check nested ignored", checkBoolValue(!included && err == nil, true, nested, err))
+
+	last := p.Collection[2].(*DTD.ConditionalSection)
+	t.Run("Check last keyword", checkStrValue(last.Keyword, "INCLUDE", last, nil))
+	t.Run("Check last blocks", checkIntValue(len(last.Blocks), 2, last, nil))
+	t.Run("Check last element", checkStrValue(last.Blocks[1].(DTD.Named).GetName(), "c", last, nil))
+
+	t.Run("Render DTD", render(p))
+}
+
+// TestConditionalReference Test a reference in a conditional section is kept in it
+// and expanded only when the section is included
+func TestConditionalReference(t *testing.T) {
+	for keyword, expanded := range map[string]int{"IGNORE": 1, "INCLUDE": 3} {
+		fsys := fstest.MapFS{
+			"main.dtd": {Data: []byte("<!ENTITY % use \"" + keyword + "\">\n" +
+				"<![%use;[\n<!ENTITY % mod SYSTEM \"mod.ent\">\n%mod;\n]]>\n")},
+			"mod.ent": {Data: []byte("<!ELEMENT x EMPTY>\n")},
+		}
+
+		dir := t.TempDir()
+		p := newParser(dir)
+		p.IgnoreExtRefIssue = false
+		p.SetFS(fsys)
+
+		if err := p.Parse("main.dtd"); err != nil {
+			t.Fatalf("Parsing failed: %v", err)
+		}
+
+		section := p.Collection[1].(*DTD.ConditionalSection)
+		t.Run("Check section blocks", checkIntValue(len(section.Blocks), 2, section, nil))
+		t.Run("Check reference", checkStrValue(section.Blocks[1].Render(), "%mod;", section, nil))
+
+		if err := p.Expand(); err != nil {
+			t.Fatalf("Expansion failed: %v", err)
+		}
+
+		t.Run("Check expanded "+keyword, checkIntValue(len(p.Expanded), expanded, p.Expanded, nil))
+
+		if err := p.Render(""); err != nil {
+			t.Fatalf("Rendering failed: %v", err)
+		}
+
+		// the reference is rendered in the section
+		rendered := newParser(t.TempDir())
+
+		if err := rendered.Parse(filepath.Join(dir, "main.dtd")); err != nil {
+			t.Fatalf("Parsing the rendered DTD failed: %v", err)
+		}
+
+		t.Run("Check rendered count", checkIntValue(len(rendered.Collection), 2, rendered.Collection, nil))
+
+		section = rendered.Collection[1].(*DTD.ConditionalSection)
+		t.Run("Check rendered reference", checkStrValue(section.Blocks[1].Render(), "%mod;", section, nil))
+	}
+}
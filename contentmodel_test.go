@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+	"github.com/blefort/DTDParser/scanner"
+)
+
+// TestParseContentModel Test the content model tree
+func TestParseContentModel(t *testing.T) {
+
+	c, err := scanner.ParseContentModel("(title, (para | list)*, note?)")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Check type", checkIntValue(c.Type, DTD.CONTENT_CHILDREN, c, nil))
+	t.Run("Check root type", checkIntValue(c.Root.Type, DTD.PARTICLE_SEQUENCE, c.Root, nil))
+	t.Run("Check root children", checkIntValue(len(c.Root.Children), 3, c.Root, nil))
+
+	group := c.Root.Children[1]
+	t.Run("Check group type", checkIntValue(group.Type, DTD.PARTICLE_CHOICE, group, nil))
+	t.Run("Check group occurrence", checkIntValue(group.Occurrence, DTD.ZERO_OR_MORE, group, nil))
+	t.Run("Check group first name", checkStrValue(group.Children[0].Name, "para", group, nil))
+
+	note := c.Root.Children[2]
+	t.Run("Check name occurrence", checkIntValue(note.Occurrence, DTD.OPTIONAL, note, nil))
+
+	names := c.Names()
+	t.Run("Check names count", checkIntValue(len(names), 4, names, nil))
+	t.Run("Check last name", checkStrValue(names[3], "note", names, nil))
+
+	mixed, err := scanner.ParseContentModel("(#PCDATA|b|%ph;)*")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Check mixed type", checkIntValue(mixed.Type, DTD.CONTENT_MIXED, mixed, nil))
+	t.Run("Check entity particle", checkIntValue(mixed.Root.Children[2].Type, DTD.PARTICLE_ENTITY, mixed.Root, nil))
+	t.Run("Check entity particle name", checkStrValue(mixed.Root.Children[2].Name, "ph", mixed.Root, nil))
+
+	for _, s := range []string{"(#PCDATA)", "(#PCDATA)*"} {
+		text, err := scanner.ParseContentModel(s)
+
+		if err != nil {
+			t.Fatalf("Unexpected error on '%s': %v", s, err)
+		}
+		t.Run("Check text only "+s, checkIntValue(text.Type, DTD.CONTENT_MIXED, text, nil))
+	}
+}
+
+// TestParseContentModelErrors Test invalid content models
+func TestParseContentModelErrors(t *testing.T) {
+
+	invalids := []string{
+		"",
+		"title",
+		"(a, b | c)",
+		"(a, b",
+		"(#PCDATA | a)",
+		"(#PCDATA | (a, b))*",
+		"(#PCDATA)+",
+		"(#PCDATA)?",
+		"(#PCDATA*)",
+		"(a | #PCDATA)",
+		"(a | #PCDATA)*",
+		"(a, (#PCDATA | b)*)",
+		"(a) b",
+	}
+
+	for _, s := range invalids {
+		if _, err := scanner.ParseContentModel(s); err == nil {
+			t.Errorf("Content model '%s' should not be valid", s)
+		}
+	}
+}
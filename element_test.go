@@ -1,14 +1,23 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/blefort/DTDParser/DTD"
 )
 
+// ElementTestResult struct to test elements
+// Content is the rendered content model
+type ElementTestResult struct {
+	Name    string
+	Value   string
+	Content string
+}
+
 // loadElementTests Load element tests
-func loadElementTests(file string) []DTD.Element {
-	var tests []DTD.Element
+func loadElementTests(file string) []ElementTestResult {
+	var tests []ElementTestResult
 	loadJSON(file, &tests)
 	return tests
 }
@@ -17,29 +26,25 @@ func loadElementTests(file string) []DTD.Element {
 func TestParseElementBlock(t *testing.T) {
 	// - parse the DTD test
 	// - compare it to data stored in a json file
-	// - render it in the tmp dir
-	testElementDTD(t, "tests/element.dtd", true)
+	// - render it in a temporary dir
+	tmp := t.TempDir()
+	testElementDTD(t, "tests/element.dtd", tmp)
 
 	// - load the generated DTD
 	// - compare it to data stored in a json file
-	testElementDTD(t, "tmp/element.dtd", false)
+	testElementDTD(t, filepath.Join(tmp, "element.dtd"), t.TempDir())
 }
 
 // testElementDTD main tests for elementDTD
-func testElementDTD(t *testing.T, path string, recreate bool) {
-	var tests []DTD.Element
-	var dir string
-
-	if recreate {
-		dir = "tmp"
-	} else {
-		dir = "tmp2"
-	}
+func testElementDTD(t *testing.T, path string, dir string) {
+	var tests []ElementTestResult
 
 	// New parser
 	p := newParser(dir)
 
-	p.Parse(path)
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
 	tests = loadElementTests("tests/element.json")
 
 	if len(p.Collection) != len(tests) {
@@ -49,10 +54,17 @@ func testElementDTD(t *testing.T, path string, recreate bool) {
 
 	for idx, test := range tests {
 
-		parsedBlock := p.Collection[idx]
+		parsedBlock := p.Collection[idx].(*DTD.Element)
 
-		t.Run("Check name", checkStrValue(parsedBlock.GetName(), test.Name, parsedBlock, test))
+		t.Run("Check name", checkStrValue(parsedBlock.Name, test.Name, parsedBlock, test))
 		t.Run("Check value", checkStrValue(parsedBlock.GetValue(), test.Value, parsedBlock, test))
+
+		content := parsedBlock.Content
+		if content == nil {
+			t.Errorf("No content model parsed for '%s'", parsedBlock.Name)
+			continue
+		}
+		t.Run("Check content model", checkStrValue(content.Render(), test.Content, parsedBlock, test))
 	}
 	t.Run("Render DTD", render(p))
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+	"github.com/blefort/DTDParser/charset"
+)
+
+// TestEncoding Test detection and transcoding of the DTD encoding
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		path     string
+		forced   string
+		encoding string
+	}{
+		{"tests/latin1.dtd", "", charset.Latin1},
+		{"tests/utf16.dtd", "", charset.UTF16LE},
+		{"tests/nodecl-latin1.dtd", "latin1", charset.Latin1},
+	}
+
+	for _, test := range tests {
+		p := newParser(t.TempDir())
+
+		if test.forced != "" {
+			if err := p.SetEncoding(test.forced); err != nil {
+				t.Fatalf("Setting encoding failed: %v", err)
+			}
+		}
+
+		if err := p.Parse(test.path); err != nil {
+			t.Fatalf("Parsing '%s' failed: %v", test.path, err)
+		}
+
+		t.Run("Check encoding", checkStrValue(p.Encoding, test.encoding, p, nil))
+
+		for _, block := range p.Collection {
+			if comment, ok := block.(*DTD.Comment); ok {
+				t.Run("Check comment", checkStrValue(comment.Value, "café", comment, nil))
+			}
+		}
+	}
+}
+
+// TestEncodingErrors Test DTDs that can't be decoded
+func TestEncodingErrors(t *testing.T) {
+	var decodeErr *charset.DecodeError
+	var unsupportedErr *charset.UnsupportedError
+
+	p := newParser(t.TempDir())
+
+	if err := p.Parse("tests/nodecl-latin1.dtd"); !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected a decode error, got %v", err)
+	}
+
+	t.Run("Check offset", checkIntValue(decodeErr.Offset, 8, decodeErr, nil))
+
+	if err := p.SetEncoding("EBCDIC"); !errors.As(err, &unsupportedErr) {
+		t.Fatalf("Expected an unsupported encoding error, got %v", err)
+	}
+}
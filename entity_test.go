@@ -1,6 +1,10 @@
 package main
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/blefort/DTDParser/DTD"
@@ -16,42 +20,53 @@ func loadEntityTests(file string) []DTD.Entity {
 func TestParseEntityBlock(t *testing.T) {
 	// - parse the DTD test
 	// - compare it to data stored in a json file
-	// - render it in the tmp dir
+	// - render it in a temporary dir
 	log.Warn("Start tests on 'tests/entity.dtd'")
-	testEntityDTD(t, "tests/entity.dtd", true)
+	tmp := t.TempDir()
+	testEntityDTD(t, "tests/entity.dtd", tmp)
 
 	// - load the generated DTD
 	// - compare it to data stored in a json file
-	//	log.Warn("Start tests on generated 'tmp/entity.dtd'")
-	testEntityDTD(t, "tmp/entity.dtd", false)
+	//	log.Warn("Start tests on generated the rendered 'entity.dtd'")
+	testEntityDTD(t, filepath.Join(tmp, "entity.dtd"), t.TempDir())
 }
 
 // testEntityDTD Main testing func for entity
-func testEntityDTD(t *testing.T, path string, recreate bool) {
+func testEntityDTD(t *testing.T, path string, dir string) {
 	var tests []DTD.Entity
 
-	var dir string
-
-	if recreate {
-		dir = "tmp"
-	} else {
-		dir = "tmp2"
-	}
-
 	// New parser
 	p := newParser(dir)
-	p.Parse(path)
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
 
 	tests = loadEntityTests("tests/entity.json")
 
-	if len(p.Collection) != len(tests) {
-		t.Errorf("Number of elements in the collection (%d) differs from number of tests (%d), please update either your DTD test or the corresponding json file", len(p.Collection), len(tests))
+	// the reference following hi-d-dec is kept in place
+	var entities []*DTD.Entity
+	var references []*DTD.Reference
+
+	for _, block := range p.Collection {
+		switch b := block.(type) {
+		case *DTD.Entity:
+			entities = append(entities, b)
+		case *DTD.Reference:
+			references = append(references, b)
+		}
+	}
+
+	if len(entities) != len(tests) {
+		t.Errorf("Number of entities in the collection (%d) differs from number of tests (%d), please update either your DTD test or the corresponding json file", len(entities), len(tests))
 		t.SkipNow()
 	}
 
+	t.Run("Check references", checkIntValue(len(references), 1, references, nil))
+	t.Run("Check reference", checkStrValue(p.Collection[7].(DTD.Named).GetName(), "hi-d-dec", p.Collection[7], nil))
+
 	for idx, test := range tests {
 
-		entityBlock := p.Collection[idx].(*DTD.Entity)
+		entityBlock := entities[idx]
 
 		t.Run("Check name", checkStrValue(entityBlock.Name, test.Name, entityBlock, test))
 		t.Run("Check value", checkStrValue(entityBlock.Value, test.Value, entityBlock, test))
@@ -63,3 +78,50 @@ func testEntityDTD(t *testing.T, path string, recreate bool) {
 	}
 	t.Run("Render DTD", render(p))
 }
+
+// TestUnparsedEntity Test an unparsed entity keeps its notation and general
+// external entities are not read
+func TestUnparsedEntity(t *testing.T) {
+	dir := t.TempDir()
+	p := newParser(dir)
+	p.IgnoreExtRefIssue = false
+
+	src := "<!NOTATION gif SYSTEM \"image/gif\">\n" +
+		"<!ENTITY logo SYSTEM \"logo.gif\" NDATA gif>\n" +
+		"<!ENTITY chapter SYSTEM \"missing.xml\">\n"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	logo := p.Collection[1].(*DTD.Entity)
+	t.Run("Check notation", checkStrValue(logo.Notation, "gif", logo, nil))
+	t.Run("Check render", checkStrValue(logo.Render(), "<!ENTITY logo  SYSTEM  \"logo.gif\" NDATA gif\n>\n", logo, nil))
+
+	data, err := json.Marshal(logo)
+
+	if err != nil {
+		t.Fatalf("Marshalling failed: %v", err)
+	}
+
+	t.Run("Check JSON", checkBoolValue(strings.Contains(string(data), `"notation":"gif"`), true, string(data), nil))
+
+	if err := p.Render(""); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(dir, "inline.dtd"))
+
+	if err != nil {
+		t.Fatalf("Reading DTD failed: %v", err)
+	}
+
+	rendered := newParser(t.TempDir())
+
+	if err := rendered.ParseReader("rendered.dtd", strings.NewReader(string(written))); err != nil {
+		t.Fatalf("Parsing the rendered DTD failed: %v", err)
+	}
+
+	logo = rendered.Collection[1].(*DTD.Entity)
+	t.Run("Check rendered notation", checkStrValue(logo.Notation, "gif", logo, nil))
+}
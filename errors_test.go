@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+	DTDParser "github.com/blefort/DTDParser/parser"
+	"github.com/blefort/DTDParser/scanner"
+)
+
+// TestSyntaxError Test a malformed DTD is reported as a syntax error
+func TestSyntaxError(t *testing.T) {
+	var syntaxErr *scanner.SyntaxError
+
+	p := newParser(t.TempDir())
+	err := p.Parse("tests/malformed.dtd")
+
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected a syntax error, got '%v'", err)
+	}
+
+	t.Run("Check file", checkStrValue(syntaxErr.File, "tests/malformed.dtd", syntaxErr, nil))
+	t.Run("Check line", checkIntValue(syntaxErr.Line, 2, syntaxErr, nil))
+}
+
+// TestExternalReferenceError Test a missing external DTD is reported
+func TestExternalReferenceError(t *testing.T) {
+	var extErr *DTDParser.ExternalReferenceError
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	err := p.Parse("tests/missing_external.dtd")
+
+	if !errors.As(err, &extErr) {
+		t.Fatalf("Expected an external reference error, got '%v'", err)
+	}
+
+	t.Run("Check entity", checkStrValue(extErr.Entity, "missing", extErr, nil))
+	t.Run("Check url", checkStrValue(extErr.Url, "missing.ent", extErr, nil))
+	t.Run("Check line", checkIntValue(extErr.Line, 2, extErr, nil))
+
+	// ignored
+	p = newParser(t.TempDir())
+	if err := p.Parse("tests/missing_external.dtd"); err != nil {
+		t.Errorf("Missing external DTD should be ignored, got '%v'", err)
+	}
+}
+
+// TestOutputExistsError Test an existing output is not overwritten
+func TestOutputExistsError(t *testing.T) {
+	var existsErr *DTDParser.OutputExistsError
+
+	p := newParser(t.TempDir())
+	p.Overwrite = false
+
+	if err := p.Parse("tests/element.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.Render(""); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+
+	if err := p.Render(""); !errors.As(err, &existsErr) {
+		t.Errorf("Expected an output exists error, got '%v'", err)
+	}
+
+	p.Overwrite = true
+	if err := p.Render(""); err != nil {
+		t.Errorf("Rendering with overwrite failed: %v", err)
+	}
+}
+
+// TestUnknownFormatter Test an unknown formatter is rejected
+func TestUnknownFormatter(t *testing.T) {
+	var formatterErr *DTDParser.UnknownFormatterError
+
+	p := newParser(t.TempDir())
+
+	if err := p.SetFormatter("yaml"); !errors.As(err, &formatterErr) {
+		t.Errorf("Expected an unknown formatter error, got '%v'", err)
+	}
+}
+
+// TestErrorRecovery Test the parsing goes on after malformed blocks and reports all of them
+func TestErrorRecovery(t *testing.T) {
+	var syntaxErrs scanner.ErrorList
+
+	p := newParser(t.TempDir())
+	err := p.Parse("tests/errors.dtd")
+
+	if !errors.As(err, &syntaxErrs) {
+		t.Fatalf("Expected a list of syntax errors, got '%v'", err)
+	}
+
+	if len(syntaxErrs) != 5 {
+		t.Fatalf("Number of errors (%d) differs from 5: %v", len(syntaxErrs), err)
+	}
+
+	lines := []int{2, 4, 6, 9, 10}
+
+	for i, line := range lines {
+		t.Run("Check line", checkIntValue(syntaxErrs[i].Line, line, syntaxErrs[i], nil))
+	}
+
+	t.Run("Check column", checkIntValue(syntaxErrs[1].Column, 18, syntaxErrs[1], nil))
+	t.Run("Check expected", checkStrValue(syntaxErrs[4].Expected, "'>'", syntaxErrs[4], nil))
+
+	// well formed blocks are kept
+	if len(p.Collection) != 4 {
+		t.Fatalf("Number of blocks (%d) differs from 4", len(p.Collection))
+	}
+
+	kinds := []int{DTD.ELEMENT, DTD.ELEMENT, DTD.CONDITIONAL, DTD.ELEMENT}
+
+	for i, kind := range kinds {
+		t.Run("Check block", checkIntValue(p.Collection[i].Kind(), kind, p.Collection[i], nil))
+	}
+
+	names := map[int]string{0: "title", 1: "para", 3: "item"}
+
+	for i, name := range names {
+		t.Run("Check name", checkStrValue(p.Collection[i].(DTD.Named).GetName(), name, p.Collection[i], nil))
+	}
+
+	section := p.Collection[2].(*DTD.ConditionalSection)
+	t.Run("Check section blocks", checkIntValue(len(section.Blocks), 1, section, nil))
+}
+
+// TestUnterminatedLiteral Test the declarations following an unterminated literal are recovered
+func TestUnterminatedLiteral(t *testing.T) {
+	var syntaxErrs scanner.ErrorList
+
+	// source and line of the last declaration
+	sources := map[string]int{
+		"<!ENTITY a \"oops>\n<!ELEMENT b EMPTY>\n<!ELEMENT c EMPTY>\n": 3,
+		"<!ENTITY a 'oops <!ELEMENT b EMPTY>\n<!ELEMENT c EMPTY>\n":    2,
+	}
+
+	for src, line := range sources {
+		p := newParser(t.TempDir())
+		err := p.ParseReader("inline.dtd", strings.NewReader(src))
+
+		if !errors.As(err, &syntaxErrs) {
+			t.Fatalf("Expected a list of syntax errors, got '%v'", err)
+		}
+
+		t.Run("Check errors", checkIntValue(len(syntaxErrs), 1, syntaxErrs, nil))
+		t.Run("Check line", checkIntValue(syntaxErrs[0].Line, 1, syntaxErrs[0], nil))
+
+		if len(p.Collection) != 2 {
+			t.Fatalf("Number of blocks (%d) differs from 2: %#v", len(p.Collection), p.Collection)
+		}
+
+		t.Run("Check first block", checkStrValue(p.Collection[0].(DTD.Named).GetName(), "b", p.Collection[0], nil))
+		t.Run("Check second block", checkStrValue(p.Collection[1].(DTD.Named).GetName(), "c", p.Collection[1], nil))
+		t.Run("Check position", checkIntValue(p.Collection[1].Pos().Line, line, p.Collection[1], nil))
+	}
+}
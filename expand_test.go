@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// TestExpand Test expansion of parameter entities
+func TestExpand(t *testing.T) {
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+
+	if err := p.Parse("tests/expand.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.Expand(); err != nil {
+		t.Fatalf("Expansion failed: %v", err)
+	}
+
+	expected := []string{"title", "global-atts", "local.mode", "mods", "extra", "note", "concept", "concept", "body"}
+
+	if len(p.Expanded) != len(expected) {
+		t.Fatalf("Number of expanded blocks (%d) differs from %d: %#v", len(p.Expanded), len(expected), p.Expanded)
+	}
+
+	for idx, name := range expected {
+		t.Run("Check name", checkStrValue(p.Expanded[idx].(DTD.Named).GetName(), name, p.Expanded[idx], nil))
+	}
+
+	title := p.Expanded[0].(*DTD.Entity)
+	t.Run("Check first declaration wins", checkStrValue(title.Value, "title", title, nil))
+
+	concept := p.Expanded[6].(*DTD.Element)
+	t.Run("Check content model", checkStrValue(concept.Content.Render(), "((title),body?,note*)", concept, nil))
+
+	attlist := p.Expanded[7].(*DTD.Attlist)
+	t.Run("Check attributes count", checkIntValue(len(attlist.Attributes), 3, attlist, nil))
+	t.Run("Check first attribute", checkStrValue(attlist.Attributes[0].Name, "id", attlist, nil))
+	t.Run("Check last attribute", checkStrValue(attlist.Attributes[2].Name, "lang", attlist, nil))
+
+	// the original collection is kept
+	reference := p.Collection[5].(*DTD.Reference)
+	t.Run("Check original reference", checkStrValue(reference.Name, "mods", reference, nil))
+
+	original := p.Collection[6].(*DTD.Element)
+	t.Run("Check original value", checkStrValue(original.Value, " ((%title;), body?, %extra;)", original, nil))
+	t.Run("Check original count", checkIntValue(len(p.Collection), 10, p.Collection, nil))
+}
+
+// TestExpandAttributeTypes Test parameter entities used as attribute types and default values
+func TestExpandAttributeTypes(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	src := "<!ENTITY % yesno \"(yes|no)\">\n" +
+		"<!ENTITY % dflt \"'no'\">\n" +
+		"<!ATTLIST a b %yesno; #IMPLIED c CDATA %dflt; d NMTOKEN #FIXED %dflt;>\n" +
+		"<!ELEMENT a EMPTY>\n"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	original := p.Collection[2].(*DTD.Attlist)
+	t.Run("Check unparsed attributes", checkIntValue(len(original.Attributes), 0, original, nil))
+	t.Run("Check raw definitions", checkStrValue(original.Render(), "<!ATTLIST a b %yesno; #IMPLIED c CDATA %dflt; d NMTOKEN #FIXED %dflt;>\n", original, nil))
+
+	if err := p.Expand(); err != nil {
+		t.Fatalf("Expansion failed: %v", err)
+	}
+
+	attlist := p.Expanded[2].(*DTD.Attlist)
+
+	if len(attlist.Attributes) != 3 {
+		t.Fatalf("Number of attributes (%d) differs from 3: %#v", len(attlist.Attributes), attlist)
+	}
+
+	b, c, d := attlist.Attributes[0], attlist.Attributes[1], attlist.Attributes[2]
+	t.Run("Check type", checkIntValue(b.Type, DTD.ENUM_ENUM, b, nil))
+	t.Run("Check enumeration", checkStrValue(strings.Join(b.Enumeration, "|"), "yes|no", b, nil))
+	t.Run("Check default kind", checkIntValue(c.DefaultKind, DTD.DEFAULT_VALUE, c, nil))
+	t.Run("Check default", checkStrValue(c.DefaultValue, "no", c, nil))
+	t.Run("Check fixed", checkIntValue(d.DefaultKind, DTD.DEFAULT_FIXED, d, nil))
+	t.Run("Check fixed value", checkStrValue(d.DefaultValue, "no", d, nil))
+	t.Run("Check expanded value", checkStrValue(attlist.Value, "", attlist, nil))
+}
+
+// TestExpandReference Test a reference is expanded where it appears,
+// with the entities declared before it
+func TestExpandReference(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.dtd": {Data: []byte("<!ENTITY % mod SYSTEM \"mod.ent\">\n" +
+			"<!ENTITY % foo \"(a)\">\n" +
+			"%mod;\n" +
+			"%undeclared;\n")},
+		"mod.ent": {Data: []byte("<!ENTITY % foo \"(b)\">\n<!ELEMENT x %foo;>\n")},
+	}
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	schema, err := p.ParseSchema("main.dtd")
+
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	reference := p.Collection[2].(*DTD.Reference)
+	t.Run("Check reference", checkStrValue(reference.Name, "mod", reference, nil))
+	t.Run("Check reference line", checkIntValue(reference.Position.Line, 3, reference, nil))
+
+	mod := p.Collection[0].(*DTD.Entity)
+	t.Run("Check not exported", checkBoolValue(mod.Exported, false, mod, nil))
+
+	x := schema.Elements["x"]
+
+	if x == nil {
+		t.Fatalf("Element 'x' not expanded: %#v", schema.Blocks)
+	}
+
+	t.Run("Check first declaration wins", checkStrValue(x.Content.Render(), "(a)", x, nil))
+
+	last := schema.Blocks[len(schema.Blocks)-1]
+	t.Run("Check undeclared reference kept", checkStrValue(last.Render(), "%undeclared;", last, nil))
+}
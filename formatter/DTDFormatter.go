@@ -6,6 +6,7 @@
 package formatter
 
 import (
+	"fmt"
 	"io"
 	"os"
 
@@ -27,125 +28,171 @@ func NewDTDFormatter(log *zap.SugaredLogger) *DTDFormatter {
 }
 
 // Render Render DTD blocks
-func (ft *DTDFormatter) Render(collection *[]DTD.IDTDBlock, path string) {
+func (ft *DTDFormatter) Render(collection *[]DTD.IDTDBlock, path string) error {
 
 	// export every blocks
 	for _, block := range *collection {
 		//p.Log.Debugf("Exporting block: %#v ", block)
-		switch block.(type) {
-		case *DTD.Attlist:
-			ft.writeToFile(path, ft.RenderAttlist(block))
-		case *DTD.Element:
-			ft.writeToFile(path, ft.RenderElement(block))
-		case *DTD.Comment:
-			ft.writeToFile(path, ft.RenderComment(block))
-		case *DTD.Entity:
-			ft.writeToFile(path, ft.RenderEntity(block))
-		case *DTD.Notation:
-			ft.writeToFile(path, ft.RenderNotation(block))
-		default:
-			panic("unidentified block")
+		s, err := ft.RenderBlock(block)
+
+		if err != nil {
+			return err
+		}
+
+		if err := ft.writeToFile(path, s+"\n\n"); err != nil {
+			return err
 		}
-		//c := block.Render() + "\n"
-		ft.writeToFile(path, "\n\n")
 	}
 
+	return nil
+}
+
+// RenderBlock Render a DTD block
+func (ft *DTDFormatter) RenderBlock(block DTD.IDTDBlock) (string, error) {
+	switch b := block.(type) {
+	case *DTD.Attlist:
+		return ft.RenderAttlist(b), nil
+	case *DTD.Element:
+		return ft.RenderElement(b), nil
+	case *DTD.Comment:
+		return ft.RenderComment(b), nil
+	case *DTD.Entity:
+		return ft.RenderEntity(b), nil
+	case *DTD.Notation:
+		return ft.RenderNotation(b), nil
+	case *DTD.ConditionalSection:
+		return ft.RenderConditionalSection(b)
+	case *DTD.XMLDecl:
+		return ft.RenderXMLDecl(b), nil
+	case *DTD.ProcessingInstruction:
+		return ft.RenderProcessingInstruction(b), nil
+	case *DTD.Reference:
+		return ft.RenderReference(b), nil
+	}
+	return "", fmt.Errorf("unidentified block %T", block)
+}
+
+// RenderConditionalSection render a conditional section and its blocks
+// the raw content is kept when the section was not scanned
+func (ft *DTDFormatter) RenderConditionalSection(c *DTD.ConditionalSection) (string, error) {
+
+	if c.Keyword == "IGNORE" {
+		return c.Render(), nil
+	}
+
+	content := "\n"
+
+	for _, block := range c.Blocks {
+		s, err := ft.RenderBlock(block)
+
+		if err != nil {
+			return "", err
+		}
+		content += s + "\n"
+	}
+
+	return join("<![", c.Keyword, "[", content, "]]>"), nil
 }
 
 // RenderAttlist Render an ATTLIST
-func (ft *DTDFormatter) RenderAttlist(b DTD.IDTDBlock) string {
-	attributes := "\n"
+func (ft *DTDFormatter) RenderAttlist(a *DTD.Attlist) string {
+	if a.Value != "" {
+		return join("<!ATTLIST ", a.Name, "\n", ft.delimitter, a.Value, "\n>")
+	}
 
-	extra := b.GetExtra()
+	attributes := "\n"
 
-	for _, attr := range extra.Attributes {
+	for _, attr := range a.Attributes {
 		attributes += ft.RenderAttribute(attr)
 	}
 
-	return join("<!ATTLIST ", b.GetName(), " ", attributes, ">")
+	return join("<!ATTLIST ", a.Name, " ", attributes, ">")
 }
 
 // RenderAttlist Render an Element
-func (ft *DTDFormatter) RenderElement(b DTD.IDTDBlock) string {
-	return join("<!ELEMENT ", b.GetName(), " ", b.GetValue(), ">")
+func (ft *DTDFormatter) RenderElement(e *DTD.Element) string {
+	return join("<!ELEMENT ", e.Name, " ", e.GetValue(), ">")
 }
 
 // RenderComment render a comment
-func (ft *DTDFormatter) RenderComment(b DTD.IDTDBlock) string {
-	return "<!--" + b.GetValue() + "-->"
+func (ft *DTDFormatter) RenderComment(c *DTD.Comment) string {
+	return "<!--" + c.Value + "-->"
 }
 
 // RenderEntity render an entity
-func (ft *DTDFormatter) RenderEntity(b DTD.IDTDBlock) string {
+func (ft *DTDFormatter) RenderEntity(e *DTD.Entity) string {
 	var m string
 	var eType string
 	var exportedStr string
 	var url string
 
-	extra := b.GetExtra()
-
-	if extra.IsParameter {
+	if e.Parameter {
 		m = " % "
 	} else {
 		m = " "
 	}
 
-	if extra.IsPublic {
+	if e.Public {
 		eType += " PUBLIC "
 	}
-	if extra.IsSystem {
+	if e.System {
 		eType += " SYSTEM "
 	}
 
-	if extra.IsExported {
-		exportedStr = join("\n%", b.GetName(), ";")
+	if e.Exported {
+		exportedStr = join("\n%", e.Name, ";")
 	}
 
-	if extra.Url != "" {
-		url = renderQuoted(extra.Url)
+	if e.Url != "" {
+		url = " " + DTD.Quote(e.Url, e.UrlQuote)
 	}
 
-	return join("<!ENTITY", m, b.GetName(), " ", eType, "\"\n", ft.delimitter, b.GetValue(), "\n\"", url, ">", exportedStr)
-}
+	if e.Notation != "" {
+		url += " NDATA " + e.Notation
+	}
 
-// RenderComment render a comment
-func (ft *DTDFormatter) RenderNotation(b DTD.IDTDBlock) string {
-	return b.Render()
+	// a system entity has no value
+	value := DTD.Quote(e.Value, e.Quote)
+	if e.System && !e.Public {
+		value = ""
+	}
+
+	return join("<!ENTITY", m, e.Name, " ", eType, value, url, ">", exportedStr)
 }
 
-// RenderAttribute Render an attribute
-func (ft *DTDFormatter) RenderAttribute(a DTD.Attribute) string {
-	s := ft.delimitter
+// RenderXMLDecl render a text declaration
+// the DTD is written in UTF-8 whatever the encoding it was read in
+func (ft *DTDFormatter) RenderXMLDecl(x *DTD.XMLDecl) string {
+	decl := *x
 
-	if a.Name != "" {
-		s += a.Name + ft.delimitter
+	if decl.Encoding != "" {
+		decl.Encoding = "UTF-8"
 	}
+	return decl.Render()
+}
 
-	s += ft.AttributeType(a.Type) + " "
-
-	if a.Fixed {
-		s += " #FIXED "
-	}
+// RenderProcessingInstruction render a processing instruction
+func (ft *DTDFormatter) RenderProcessingInstruction(pi *DTD.ProcessingInstruction) string {
+	return pi.Render()
+}
 
-	if a.Value != "" && !a.IsEntity && a.Fixed {
-		s += renderQuoted(a.Value)
-	} else if a.Value != "" && !a.IsEntity && !a.Fixed {
-		s += a.Value
-	} else if a.Value != "" && a.IsEntity {
-		s += a.Value
-	}
+// RenderReference render a parameter entity reference
+func (ft *DTDFormatter) RenderReference(r *DTD.Reference) string {
+	return r.Render()
+}
 
-	if a.Implied {
-		s += " #IMPLIED "
-	}
+// RenderNotation render a notation
+func (ft *DTDFormatter) RenderNotation(n *DTD.Notation) string {
+	return n.Render()
+}
 
-	if a.Required {
-		s += " #REQUIRED "
+// RenderAttribute Render an attribute
+func (ft *DTDFormatter) RenderAttribute(a DTD.Attribute) string {
+	if a.IsEntity {
+		return join(ft.delimitter, a.Value, "\n")
 	}
 
-	s += "\n"
-
-	return s
+	return join(ft.delimitter, a.Name, ft.delimitter, a.RenderType(), " ", a.RenderDefault(), "\n")
 }
 
 // writeToFile write to a DTD file
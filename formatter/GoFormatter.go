@@ -6,19 +6,48 @@
 package formatter
 
 import (
+	"go/format"
 	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/blefort/DTDParser/DTD"
 	"go.uber.org/zap"
 )
 
-// IDTDBlock Interface for DTD block
+// GoFormatter renders the elements of a DTD as Go structs to be used with encoding/xml
 type GoFormatter struct {
 	delimitter  string
 	log         *zap.SugaredLogger
 	packageName string
+	schema      *DTD.Schema
+	typeNames   map[string]string // Go type of each element
+	identifiers map[string]bool   // identifiers declared in the package
+	imports     map[string]bool
+	tokensType  string            // Go type of the lists of tokens, declared once used
+	enumTypes   map[string]string // Go type of each enumeration, by its values
+	pending     []string          // declarations rendered after the current struct
+	current     *goStruct         // struct being rendered
+	textType    string            // Go type of the character data of mixed contents, declared once used
+	marshalAttr bool              // the helper marshalling attributes is used
+	namespaces  map[string]string // namespace bound to each prefix by the xmlns attributes of the DTD
+}
+
+// xmlNamespace is the namespace bound to the xml prefix
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// childField represents an element referenced in a content model
+// multiple and optional are given by the occurrence indicators of the
+// particle and of the groups holding it
+type childField struct {
+	name     string
+	field    string
+	t        string
+	multiple bool
+	optional bool
 }
 
 // NewGoFormatter instantiate new GoFormatter struct
@@ -30,55 +59,577 @@ func NewGoFormatter(log *zap.SugaredLogger, packageName string) *GoFormatter {
 	return &f
 }
 
-// Render Render DTD blocks
-func (ft *GoFormatter) Render(collection *[]DTD.IDTDBlock, path string) {
+// Render Render the elements of a DTD schema
+// the schema must be expanded so that content models are parsed, see DTDParser.Schema
+func (ft *GoFormatter) Render(schema *DTD.Schema, path string) error {
+	var body strings.Builder
+
+	ft.schema = schema
+	ft.typeNames = make(map[string]string)
+	ft.identifiers = make(map[string]bool)
+	ft.imports = make(map[string]bool)
+	ft.tokensType = ""
+	ft.enumTypes = make(map[string]string)
+	ft.textType = ""
+	ft.marshalAttr = false
+	ft.namespaces = make(map[string]string)
+
+	elements := ft.elements()
+
+	for _, e := range elements {
+		ft.typeNames[e.Name] = ft.identifier(goName(e.Name))
+		ft.collectNamespaces(e)
+	}
+
+	// export every elements
+	for _, e := range elements {
+		body.WriteString(ft.renderStruct(e) + "\n\n")
+
+		for _, decl := range ft.pending {
+			body.WriteString(decl + "\n\n")
+		}
+		ft.pending = nil
+	}
+
+	if ft.tokensType != "" {
+		body.WriteString(ft.renderTokensType())
+	}
+
+	if ft.textType != "" {
+		body.WriteString(ft.renderTextType())
+	}
+
+	if ft.marshalAttr {
+		body.WriteString(ft.renderMarshalAttr())
+	}
+
+	src := join("// Code generated by DTDParser. DO NOT EDIT.\n\n", "package ", ft.packageName, "\n\n", ft.renderImports(), body.String())
+
+	formatted, err := format.Source([]byte(src))
+
+	if err != nil {
+		ft.log.Errorf("Generated code can't be formatted: %v", err)
+		return err
+	}
+
+	return ft.writeToFile(path, string(formatted))
+}
+
+// elements returns the elements of the schema in document order,
+// the first declaration of an element is binding
+func (ft *GoFormatter) elements() []*DTD.Element {
+	var elements []*DTD.Element
 
-	ft.writeToFile(path, "package "+ft.packageName+"\n\n")
+	for _, block := range ft.schema.Blocks {
+		if e, ok := block.(*DTD.Element); ok && ft.schema.Elements[e.Name] == e {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
 
-	// export every blocks
-	for _, block := range *collection {
-		//p.Log.Debugf("Exporting block: %#v ", block)
-		switch block.(type) {
+// collectNamespaces records the namespaces bound by the xmlns attributes of an element
+// with a default or a fixed value, the first binding of a prefix is kept.
+// The default namespace is bound to the empty prefix.
+func (ft *GoFormatter) collectNamespaces(b *DTD.Element) {
+	attributes, _ := ft.schema.Attributes(b.Name)
 
-		case *DTD.Element:
-			ft.writeToFile(path, ft.renderStruct(collection, block))
-		default:
+	for _, attr := range attributes {
+		if (attr.Name != "xmlns" && !strings.HasPrefix(attr.Name, "xmlns:")) || attr.DefaultValue == "" {
 			continue
 		}
-		ft.writeToFile(path, "\n\n")
+
+		prefix := strings.TrimPrefix(strings.TrimPrefix(attr.Name, "xmlns"), ":")
+		if _, ok := ft.namespaces[prefix]; !ok {
+			ft.namespaces[prefix] = attr.DefaultValue
+		}
+	}
+}
+
+// renderImports Render the import declaration of the packages used
+func (ft *GoFormatter) renderImports() string {
+	if len(ft.imports) == 0 {
+		return ""
+	}
+
+	var paths []string
+	for path := range ft.imports {
+		paths = append(paths, strconv.Quote(path))
 	}
+	sort.Strings(paths)
+
+	return join("import (\n", ft.delimitter, strings.Join(paths, "\n"+ft.delimitter), "\n)\n\n")
 }
 
-// RenderAttlist Render an Element
-func (ft *GoFormatter) renderStruct(collection *[]DTD.IDTDBlock, b DTD.IDTDBlock) string {
-	return join("type ", strings.Title(strings.ToLower(b.GetName())), " struct {", ft.renderStructContent(collection, b), "}")
+// renderStruct Render an Element
+// an element whose nodes are kept in order is marshalled by its own methods,
+// so is an element holding prefixed elements or attributes, see isPrefixed
+func (ft *GoFormatter) renderStruct(b *DTD.Element) string {
+	ft.current = &goStruct{name: ft.typeNames[b.Name], element: b}
+
+	doc := join("// ", ft.typeNames[b.Name], " represents the element ", b.Name, "\n")
+	s := join(doc, "type ", ft.typeNames[b.Name], " struct {", ft.renderStructContent(b), "}")
+
+	switch {
+	case len(ft.current.groups) > 0 || ft.current.hasPrefixedName():
+		for _, g := range ft.current.groups {
+			ft.pending = append(ft.pending, ft.renderNodeGroup(g))
+		}
+		ft.pending = append(ft.pending, ft.renderMarshalXML(), ft.renderUnmarshalXML())
+	case isPrefixed(b.Name):
+		ft.pending = append(ft.pending, ft.renderMarshalQName())
+	}
+	return s
 }
 
-// RenderAttlist Render an Element
-func (ft *GoFormatter) renderStructContent(collection *[]DTD.IDTDBlock, b DTD.IDTDBlock) string {
+// renderStructContent Render the fields of an Element
+// fields of attributes are named after the fields of elements
+func (ft *GoFormatter) renderStructContent(b *DTD.Element) string {
+	names := map[string]bool{"XMLName": true}
+
+	elements := ft.renderBlockElements(b, names)
+
 	content := ft.renderXMLName(b)
-	content += ft.renderBlockElements(collection, b)
+	content += ft.renderAttributes(b, names)
+	content += elements
 	return content
 }
 
-func (ft *GoFormatter) renderXMLName(b DTD.IDTDBlock) string {
-	return join("\nXMLName xml.Name `xml:\"", b.GetName(), "\"`\n")
+func (ft *GoFormatter) renderXMLName(b *DTD.Element) string {
+	ft.imports["encoding/xml"] = true
+	return join("\nXMLName xml.Name `xml:\"", localName(b.Name), "\"`\n")
 }
 
-func (ft *GoFormatter) renderBlockElements(collection *[]DTD.IDTDBlock, b DTD.IDTDBlock) string {
-	elements := ft.parseElementValue(b)
+// renderBlockElements Render a field for each element referenced in the content model
+func (ft *GoFormatter) renderBlockElements(b *DTD.Element, names map[string]bool) string {
+	elements := ft.parseElementValue(b, names)
 	content := ""
 	for _, el := range *elements {
-		content += "\n" + el + "\n"
+		content += el + "\n"
 	}
 	return content
 }
 
-func (ft *GoFormatter) parseElementValue(b DTD.IDTDBlock) *[]string {
+// parseElementValue returns the fields of the elements referenced in the content model
+// an element is a slice when it may occur several times, a pointer when it is optional,
+// the alternatives of a choice are held by a node group.
+// An element sharing its local name with another one is not tagged, see nameCondition.
+func (ft *GoFormatter) parseElementValue(b *DTD.Element, names map[string]bool) *[]string {
 	var s []string
+
+	if b.Content == nil || b.Content.Root == nil {
+		return &s
+	}
+
+	// character data and elements are kept in order
+	if b.Content.Type == DTD.CONTENT_MIXED {
+		if len(b.Content.Names()) == 0 {
+			ft.current.text = uniqueName(names, "Text")
+			return &[]string{join(ft.current.text, " string `xml:\",chardata\"`")}
+		}
+
+		g := ft.nodeGroup(names, b.Content.Names(), true, true)
+		return &[]string{ft.renderNodeGroupField(g)}
+	}
+
+	groups := ft.choiceGroups(b.Content)
+	ft.collectFields(b.Content.Root, false, false, make(map[string]*childField), groups, names)
+
+	var qnames []string
+	for _, item := range ft.current.items {
+		if item.group != nil {
+			qnames = append(qnames, item.group.names...)
+			continue
+		}
+		qnames = append(qnames, item.field.name)
+	}
+	ft.current.shared = sharedLocalNames(qnames)
+
+	for _, item := range ft.current.items {
+		if item.group != nil {
+			s = append(s, ft.renderNodeGroupField(item.group))
+			continue
+		}
+
+		f := item.field
+		f.field = uniqueName(names, goName(f.name))
+		f.t = ft.fieldType(f)
+
+		tag := localName(f.name)
+		if ft.current.shared[f.name] {
+			tag = "-"
+		}
+		s = append(s, join(f.field, " ", f.t, " `xml:\"", tag, "\"`"))
+	}
 	return &s
 }
 
+// choiceGroups returns the groups of a content model kept in document order: choices of
+// declared elements, each of them referenced only once in the content model.
+// A choice inside a repeated group is kept in order with the other elements of the
+// outermost repeated group, which is returned instead when its elements are declared
+// and referenced once.
+func (ft *GoFormatter) choiceGroups(c *DTD.ContentModel) map[*DTD.Particle]bool {
+	groups := make(map[*DTD.Particle]bool)
+	count := make(map[string]int)
+	outer := make(map[*DTD.Particle]*DTD.Particle)
+
+	var choices []*DTD.Particle
+	var walk func(p *DTD.Particle, repeated *DTD.Particle)
+
+	walk = func(p *DTD.Particle, repeated *DTD.Particle) {
+		switch {
+		case p.Type == DTD.PARTICLE_NAME:
+			count[p.Name]++
+		case p.Type == DTD.PARTICLE_CHOICE && len(p.Children) > 1:
+			choices = append(choices, p)
+			outer[p] = repeated
+		}
+		if repeated == nil && p.IsGroup() && (p.Occurrence == DTD.ZERO_OR_MORE || p.Occurrence == DTD.ONE_OR_MORE) {
+			repeated = p
+		}
+		for _, child := range p.Children {
+			walk(child, repeated)
+		}
+	}
+	walk(c.Root, nil)
+
+	var declared func(p *DTD.Particle) bool
+
+	declared = func(p *DTD.Particle) bool {
+		switch p.Type {
+		case DTD.PARTICLE_NAME:
+			_, ok := ft.typeNames[p.Name]
+			return ok && count[p.Name] == 1
+		case DTD.PARTICLE_SEQUENCE, DTD.PARTICLE_CHOICE:
+			for _, child := range p.Children {
+				if !declared(child) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	}
+
+	for _, p := range choices {
+		r := outer[p]
+
+		if r == nil {
+			groups[p] = true
+
+			for _, child := range p.Children {
+				if child.Type != DTD.PARTICLE_NAME || !declared(child) {
+					groups[p] = false
+					break
+				}
+			}
+			continue
+		}
+
+		if _, ok := groups[r]; !ok {
+			groups[r] = declared(r)
+
+			if !groups[r] {
+				ft.log.Warnf("The order of the elements of '%s' in '%s' is not kept", r.Render(), ft.current.element.Name)
+			}
+		}
+	}
+	return groups
+}
+
+// collectFields walks a particle and adds the elements it references to the current struct
+// the particles found in groups are added as node groups
+func (ft *GoFormatter) collectFields(p *DTD.Particle, optional bool, multiple bool, seen map[string]*childField, groups map[*DTD.Particle]bool, names map[string]bool) {
+	optional = optional || p.Occurrence == DTD.OPTIONAL || p.Occurrence == DTD.ZERO_OR_MORE
+	multiple = multiple || p.Occurrence == DTD.ZERO_OR_MORE || p.Occurrence == DTD.ONE_OR_MORE
+
+	// the elements are kept in order as nodes
+	if p.IsGroup() && groups[p] {
+		for _, child := range p.Children {
+			multiple = multiple || child.Occurrence == DTD.ZERO_OR_MORE || child.Occurrence == DTD.ONE_OR_MORE
+		}
+		ft.nodeGroup(names, p.Names(), false, multiple)
+		return
+	}
+
+	switch p.Type {
+	case DTD.PARTICLE_NAME:
+		// an element referenced twice is a list
+		if f, ok := seen[p.Name]; ok {
+			f.multiple = true
+			return
+		}
+		f := childField{name: p.Name, multiple: multiple, optional: optional}
+		seen[p.Name] = &f
+		ft.current.items = append(ft.current.items, contentItem{field: &f})
+
+	case DTD.PARTICLE_SEQUENCE:
+		for _, child := range p.Children {
+			ft.collectFields(child, optional, multiple, seen, groups, names)
+		}
+
+	case DTD.PARTICLE_CHOICE:
+		// only one of the alternatives is present
+		for _, child := range p.Children {
+			ft.collectFields(child, optional || len(p.Children) > 1, multiple, seen, groups, names)
+		}
+
+	case DTD.PARTICLE_ENTITY:
+		ft.log.Warnf("Parameter entity '%%%s;' is not expanded, its elements are ignored", p.Name)
+	}
+}
+
+// fieldType returns the Go type of a field
+// an element not declared in the DTD is kept as a string
+func (ft *GoFormatter) fieldType(f *childField) string {
+	t, ok := ft.typeNames[f.name]
+
+	if !ok {
+		ft.log.Warnf("Element '%s' is not declared", f.name)
+		t = "string"
+	}
+
+	switch {
+	case f.multiple:
+		return "[]" + t
+	case f.optional:
+		return "*" + t
+	}
+	return t
+}
+
+// renderAttributes Render a field for each attribute of an element
+func (ft *GoFormatter) renderAttributes(b *DTD.Element, names map[string]bool) string {
+	content := ""
+
+	attributes, _ := ft.schema.Attributes(b.Name)
+
+	var qnames []string
+	for _, attr := range attributes {
+		qnames = append(qnames, attr.Name)
+	}
+	shared := sharedLocalNames(qnames)
+
+	for _, attr := range attributes {
+		content += ft.renderAttribute(b, attr, shared[attr.Name], names) + "\n"
+	}
+	return content
+}
+
+// renderAttribute Render the field of an attribute
+// a #REQUIRED attribute is a value, an #IMPLIED one a pointer, the value of a
+// #FIXED attribute is declared as a constant.
+// An attribute sharing its local name with another one is not tagged, see nameCondition.
+func (ft *GoFormatter) renderAttribute(b *DTD.Element, attr DTD.Attribute, shared bool, names map[string]bool) string {
+	var doc string
+
+	name := goName(attr.Name)
+	if names[name] {
+		name += "Attr"
+	}
+	name = uniqueName(names, name)
+
+	t := ft.attributeType(b, attr)
+	tag := attributeTag(attr.Name) + ",attr,omitempty"
+
+	field := goAttribute{field: name, name: attr.Name, t: t, omitempty: true, marshaler: t != "string", exact: shared}
+	ft.current.attributes = append(ft.current.attributes, &field)
+
+	switch attr.DefaultKind {
+	case DTD.DEFAULT_REQUIRED:
+		tag = attributeTag(attr.Name) + ",attr"
+		field.omitempty = false
+
+	case DTD.DEFAULT_IMPLIED:
+		if t != ft.tokensType {
+			t = "*" + t
+			field.pointer = true
+		}
+
+	case DTD.DEFAULT_VALUE:
+		doc = join("// ", name, " defaults to ", strconv.Quote(attr.DefaultValue), "\n")
+
+	case DTD.DEFAULT_FIXED:
+		c := ft.identifier(ft.typeNames[b.Name] + name)
+		doc = join("// ", name, " is fixed to ", c, "\n")
+		ft.pending = append(ft.pending, join("// ", c, " is the value of the attribute ", attr.Name, " of ", b.Name, "\n",
+			"const ", c, " = ", strconv.Quote(attr.DefaultValue)))
+	}
+
+	if shared {
+		tag = "-"
+	}
+	return join(doc, name, " ", t, " `xml:\"", tag, "\"`")
+}
+
+// attributeType returns the Go type of an attribute
+// lists of tokens are space separated in XML, enumerations have their own type
+func (ft *GoFormatter) attributeType(b *DTD.Element, attr DTD.Attribute) string {
+	switch attr.Type {
+	case DTD.ENUM_ENUM, DTD.ENUM_NOTATION:
+		if len(attr.Enumeration) > 0 {
+			return ft.enumType(b, attr)
+		}
+	case DTD.TOKEN_IDREFS, DTD.TOKEN_ENTITIES, DTD.TOKEN_NMTOKENS:
+		if ft.tokensType == "" {
+			ft.tokensType = ft.identifier("Tokens")
+		}
+		return ft.tokensType
+	}
+	return "string"
+}
+
+// enumType returns the Go type of an enumerated attribute
+// the type is declared the first time its values are found, an enumeration
+// shared by several attributes, with a parameter entity for instance, has a single type.
+func (ft *GoFormatter) enumType(b *DTD.Element, attr DTD.Attribute) string {
+	key := strings.Join(attr.Enumeration, "|")
+
+	if t, ok := ft.enumTypes[key]; ok {
+		return t
+	}
+
+	name := goName(attr.Name)
+	if ft.identifiers[name] {
+		name = ft.typeNames[b.Name] + name
+	}
+
+	t := ft.identifier(name)
+	ft.enumTypes[key] = t
+	ft.pending = append(ft.pending, ft.renderEnumType(t, b, attr))
+	return t
+}
+
+// renderEnumType Render the type of an enumeration, a constant per value,
+// its validation and its XML marshalling rejecting unknown values
+func (ft *GoFormatter) renderEnumType(t string, b *DTD.Element, attr DTD.Attribute) string {
+	ft.imports["fmt"] = true
+	ft.imports["strings"] = true
+
+	constants := make([]string, len(attr.Enumeration))
+	values := ""
+
+	for i, value := range attr.Enumeration {
+		constants[i] = ft.identifier(t + goWords(value))
+		values += join(ft.delimitter, constants[i], " ", t, " = ", strconv.Quote(value), "\n")
+	}
+
+	return join("// ", t, " is the enumeration of the attribute ", attr.Name, " of ", b.Name, "\n",
+		"type ", t, " string\n\n",
+		"// Values of ", t, "\n",
+		"const (\n", values, ")\n\n",
+		"// Valid tells if v is one of the values of ", t, "\n",
+		"func (v ", t, ") Valid() bool {\n",
+		"switch v {\ncase ", strings.Join(constants, ", "), ":\nreturn true\n}\nreturn false\n}\n\n",
+		"// MarshalXMLAttr implements xml.MarshalerAttr\n",
+		"func (v ", t, ") MarshalXMLAttr(name xml.Name) (xml.Attr, error) {\n",
+		"if v == \"\" {\nreturn xml.Attr{}, nil\n}\n",
+		"if !v.Valid() {\nreturn xml.Attr{}, fmt.Errorf(\"invalid value %q for attribute %s\", string(v), name.Local)\n}\n",
+		"return xml.Attr{Name: name, Value: string(v)}, nil\n}\n\n",
+		"// UnmarshalXMLAttr implements xml.UnmarshalerAttr\n",
+		"func (v *", t, ") UnmarshalXMLAttr(attr xml.Attr) error {\n",
+		"value := ", t, "(strings.TrimSpace(attr.Value))\n",
+		"if !value.Valid() {\nreturn fmt.Errorf(\"invalid value %q for attribute %s\", attr.Value, attr.Name.Local)\n}\n",
+		"*v = value\nreturn nil\n}")
+}
+
+// renderTokensType Render the type of the lists of tokens and its XML marshalling
+func (ft *GoFormatter) renderTokensType() string {
+	ft.imports["strings"] = true
+
+	t := ft.tokensType
+
+	return join("// ", t, " is a list of names separated by spaces: the value of IDREFS, ENTITIES and NMTOKENS attributes\n",
+		"type ", t, " []string\n\n",
+		"// MarshalXMLAttr implements xml.MarshalerAttr\n",
+		"func (t ", t, ") MarshalXMLAttr(name xml.Name) (xml.Attr, error) {\n",
+		"if len(t) == 0 {\nreturn xml.Attr{}, nil\n}\n",
+		"return xml.Attr{Name: name, Value: strings.Join(t, \" \")}, nil\n}\n\n",
+		"// UnmarshalXMLAttr implements xml.UnmarshalerAttr\n",
+		"func (t *", t, ") UnmarshalXMLAttr(attr xml.Attr) error {\n",
+		"*t = strings.Fields(attr.Value)\nreturn nil\n}\n\n")
+}
+
+// attributeTag returns the name of an attribute in a tag of encoding/xml
+// the xml prefix is bound to its namespace, other prefixes are ignored: the tag
+// matches the local name in any namespace, see sharedLocalNames
+func attributeTag(name string) string {
+	i := strings.Index(name, ":")
+
+	if i < 0 {
+		return name
+	}
+
+	if name[:i] == "xml" {
+		return xmlNamespace + " " + name[i+1:]
+	}
+	return name[i+1:]
+}
+
+// sharedLocalNames returns the prefixed and unprefixed names sharing their local name
+// with another one, their tags would collide. Names prefixed by xml are left out,
+// attributes are tagged with their namespace and elements can't use this prefix.
+func sharedLocalNames(names []string) map[string]bool {
+	count := make(map[string]int)
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, "xml:") {
+			count[localName(name)]++
+		}
+	}
+
+	shared := make(map[string]bool)
+	for _, name := range names {
+		if !strings.HasPrefix(name, "xml:") && count[localName(name)] > 1 {
+			shared[name] = true
+		}
+	}
+	return shared
+}
+
+// identifier returns a package level identifier based on name not declared yet
+func (ft *GoFormatter) identifier(name string) string {
+	return uniqueName(ft.identifiers, name)
+}
+
+// uniqueName returns name, followed by a number if it is already in names, and adds it to names
+func uniqueName(names map[string]bool, name string) string {
+	unique := name
+
+	for i := 2; names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
+
+// goName converts a DTD name to an exported Go identifier
+// the characters not allowed in Go identifiers separate words: xml:lang gives XmlLang
+func goName(name string) string {
+	s := goWords(name)
+
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// goWords returns the words of name, capitalized and joined
+func goWords(name string) string {
+	var sb strings.Builder
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		r := []rune(word)
+		sb.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return sb.String()
+}
+
 // writeToFile write to a DTD file
 func (ft *GoFormatter) writeToFile(filepath string, s string) error {
 	f, err := os.OpenFile(filepath, os.O_APPEND|os.O_WRONLY, 0700)
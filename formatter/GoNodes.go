@@ -0,0 +1,446 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package formatter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// goStruct represents the struct of an element being rendered
+// an element having node groups is marshalled by its own methods,
+// they need the fields of its attributes and of its content.
+// text is the field of the character data of an element without child elements,
+// shared holds the elements sharing their local name, matched by their namespace.
+type goStruct struct {
+	name       string
+	element    *DTD.Element
+	attributes []*goAttribute
+	groups     []*nodeGroup
+	items      []contentItem
+	text       string
+	shared     map[string]bool
+}
+
+// contentItem is either a field or a node group of a struct,
+// items are listed in the order of the content model
+type contentItem struct {
+	field *childField
+	group *nodeGroup
+}
+
+// goAttribute represents the field of an attribute
+// marshaler tells if its type implements xml.MarshalerAttr, exact if it is
+// matched by its namespace as well, its local name being shared
+type goAttribute struct {
+	field     string
+	name      string
+	t         string
+	pointer   bool
+	omitempty bool
+	marshaler bool
+	exact     bool
+}
+
+// nodeGroup represents nodes of a content model kept in document order
+// The nodes are held in an interface sealed by an unexported method, implemented
+// by the types of the elements and by the text type when text is set.
+// A group occurring several times is held in a slice.
+type nodeGroup struct {
+	field    string
+	iface    string
+	method   string
+	names    []string
+	text     bool
+	multiple bool
+}
+
+// nodeGroup returns a new group of the current struct for the elements names
+// elements not declared in the DTD are skipped
+func (ft *GoFormatter) nodeGroup(fields map[string]bool, names []string, text bool, multiple bool) *nodeGroup {
+	g := nodeGroup{
+		field:    uniqueName(fields, "Content"),
+		iface:    ft.identifier(ft.current.name + "Content"),
+		text:     text,
+		multiple: multiple,
+	}
+	g.method = "is" + g.iface
+
+	for _, name := range names {
+		if _, ok := ft.typeNames[name]; !ok {
+			ft.log.Warnf("Element '%s' is not declared, it is skipped from the content of '%s'", name, ft.current.element.Name)
+			continue
+		}
+		g.names = append(g.names, name)
+	}
+
+	if text && ft.textType == "" {
+		ft.textType = ft.identifier("Text")
+	}
+
+	ft.current.groups = append(ft.current.groups, &g)
+	ft.current.items = append(ft.current.items, contentItem{group: &g})
+	return &g
+}
+
+// renderNodeGroupField Render the field holding the nodes of a group
+func (ft *GoFormatter) renderNodeGroupField(g *nodeGroup) string {
+	if !g.multiple {
+		return join(g.field, " ", g.iface, " `xml:\"-\"`")
+	}
+	return join(g.field, " []", g.iface, " `xml:\"-\"`")
+}
+
+// renderNodeGroup Render the interface of the nodes of a group and its implementations
+func (ft *GoFormatter) renderNodeGroup(g *nodeGroup) string {
+	var types []string
+
+	if g.text {
+		types = append(types, ft.textType)
+	}
+	for _, name := range g.names {
+		types = append(types, "*"+ft.typeNames[name])
+	}
+
+	s := join("// ", g.iface, " is a node of the content of ", ft.current.element.Name, ": ", strings.Join(types, ", "), "\n",
+		"type ", g.iface, " interface {\n", g.method, "()\n}\n")
+
+	for _, t := range types {
+		s += join("\n// ", g.method, " implements ", g.iface, "\n",
+			"func (", t, ") ", g.method, "() {}\n")
+	}
+	return s
+}
+
+// hasPrefixedName tells if an element of a field or an attribute of the struct is prefixed
+func (s *goStruct) hasPrefixedName() bool {
+	for _, item := range s.items {
+		if item.field != nil && isPrefixed(item.field.name) {
+			return true
+		}
+	}
+	for _, a := range s.attributes {
+		if isPrefixed(a.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderMarshalQName Render the MarshalXML method of the current struct
+// writing it with its qualified name, its fields are marshalled from their tags
+func (ft *GoFormatter) renderMarshalQName() string {
+	s := ft.current
+
+	return join("// MarshalXML implements xml.Marshaler\n",
+		"// ", s.name, " is written with its prefix\n",
+		"func (e ", s.name, ") MarshalXML(enc *xml.Encoder, start xml.StartElement) error {\n",
+		"type element ", s.name, "\n",
+		"start = xml.StartElement{Name: xml.Name{Local: ", strconv.Quote(s.element.Name), "}}\n",
+		"return enc.EncodeElement(element(e), start)\n}")
+}
+
+// renderMarshalXML Render the MarshalXML method of the current struct
+func (ft *GoFormatter) renderMarshalXML() string {
+	s := ft.current
+
+	content := ""
+
+	for _, item := range s.items {
+		if item.group != nil {
+			content += ft.renderMarshalNodes(item.group)
+			continue
+		}
+		content += ft.renderMarshalField(item.field)
+	}
+
+	if s.text != "" {
+		content += join("if err := enc.EncodeToken(xml.CharData(e.", s.text, ")); err != nil {\nreturn err\n}\n")
+	}
+
+	return join("// MarshalXML implements xml.Marshaler\n",
+		"func (e ", s.name, ") MarshalXML(enc *xml.Encoder, start xml.StartElement) error {\n",
+		"start = xml.StartElement{Name: xml.Name{Local: ", strconv.Quote(s.element.Name), "}}\n",
+		ft.renderMarshalAttributes(),
+		"if err := enc.EncodeToken(start); err != nil {\nreturn err\n}\n",
+		content,
+		"return enc.EncodeToken(start.End())\n}")
+}
+
+// renderMarshalAttributes Render the marshalling of the attributes of the current struct
+func (ft *GoFormatter) renderMarshalAttributes() string {
+	s := ""
+
+	for _, a := range ft.current.attributes {
+		name := xmlNameLiteral(a.name)
+		value := "e." + a.field
+
+		switch {
+		case a.marshaler:
+			ft.marshalAttr = true
+			marshal := join("if err := marshalAttr(&start, ", name, ", ", value, "); err != nil {\nreturn err\n}\n")
+			if a.pointer {
+				marshal = join("if ", value, " != nil {\n", strings.Replace(marshal, value, "*"+value, 1), "}\n")
+			}
+			s += marshal
+
+		case a.pointer:
+			s += join("if ", value, " != nil {\n",
+				"start.Attr = append(start.Attr, xml.Attr{Name: ", name, ", Value: *", value, "})\n}\n")
+
+		case a.omitempty:
+			s += join("if ", value, " != \"\" {\n",
+				"start.Attr = append(start.Attr, xml.Attr{Name: ", name, ", Value: ", value, "})\n}\n")
+
+		default:
+			s += join("start.Attr = append(start.Attr, xml.Attr{Name: ", name, ", Value: ", value, "})\n")
+		}
+	}
+	return s
+}
+
+// renderMarshalField Render the marshalling of the field of an element
+// nil pointers and empty slices are not written
+func (ft *GoFormatter) renderMarshalField(f *childField) string {
+	return join("if err := enc.EncodeElement(e.", f.field, ", xml.StartElement{Name: xml.Name{Local: ", strconv.Quote(f.name), "}}); err != nil {\nreturn err\n}\n")
+}
+
+// renderMarshalNodes Render the marshalling of the nodes of a group
+func (ft *GoFormatter) renderMarshalNodes(g *nodeGroup) string {
+	if !g.multiple {
+		return join("if e.", g.field, " != nil {\n",
+			"if err := enc.Encode(e.", g.field, "); err != nil {\nreturn err\n}\n}\n")
+	}
+
+	if !g.text {
+		return join("for _, n := range e.", g.field, " {\n",
+			"if err := enc.Encode(n); err != nil {\nreturn err\n}\n}\n")
+	}
+
+	return join("for _, n := range e.", g.field, " {\n",
+		"var err error\n",
+		"if text, ok := n.(", ft.textType, "); ok {\n",
+		"err = enc.EncodeToken(xml.CharData(text))\n",
+		"} else {\nerr = enc.Encode(n)\n}\n",
+		"if err != nil {\nreturn err\n}\n}\n")
+}
+
+// renderUnmarshalXML Render the UnmarshalXML method of the current struct
+// elements are matched by their local name, and by their namespace as well when
+// their local name is shared, elements not expected are skipped
+func (ft *GoFormatter) renderUnmarshalXML() string {
+	s := ft.current
+
+	chardata := ""
+	elements := ""
+
+	var names []string
+	cases := make(map[string]string)
+
+	for _, item := range s.items {
+		if item.field != nil {
+			names = append(names, item.field.name)
+			cases[item.field.name] = ft.renderUnmarshalField(item.field)
+			continue
+		}
+
+		g := item.group
+
+		if g.text {
+			chardata = ft.renderUnmarshalText(g)
+		}
+		for _, name := range g.names {
+			c := join("n := new(", ft.typeNames[name], ")\n",
+				"if err := d.DecodeElement(n, &t); err != nil {\nreturn err\n}\n")
+
+			if g.multiple {
+				c += join("e.", g.field, " = append(e.", g.field, ", n)\n")
+			} else {
+				c += join("e.", g.field, " = n\n")
+			}
+			names = append(names, name)
+			cases[name] = c
+		}
+	}
+
+	// the elements sharing a local name are in the same case
+	done := make(map[string]bool)
+
+	for _, name := range names {
+		local := localName(name)
+
+		if !s.shared[name] {
+			elements += join("case ", strconv.Quote(local), ":\n", cases[name])
+			continue
+		}
+
+		if done[local] {
+			continue
+		}
+		done[local] = true
+
+		elements += join("case ", strconv.Quote(local), ":\n", "switch {\n")
+		for _, other := range names {
+			if s.shared[other] && localName(other) == local {
+				elements += join("case ", ft.nameCondition("t.Name", other, true), ":\n", cases[other])
+			}
+		}
+		elements += "default:\nif err := d.Skip(); err != nil {\nreturn err\n}\n}\n"
+	}
+
+	if s.text != "" {
+		chardata = join("case xml.CharData:\n", "e.", s.text, " += string(t)\n")
+	}
+
+	return join("// UnmarshalXML implements xml.Unmarshaler\n",
+		"func (e *", s.name, ") UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n",
+		"e.XMLName = start.Name\n",
+		ft.renderUnmarshalAttributes(),
+		"for {\n",
+		"tok, err := d.Token()\n",
+		"if err != nil {\nreturn err\n}\n",
+		"switch t := tok.(type) {\n",
+		chardata,
+		"case xml.StartElement:\n",
+		"switch t.Name.Local {\n",
+		elements,
+		"default:\nif err := d.Skip(); err != nil {\nreturn err\n}\n}\n",
+		"case xml.EndElement:\nreturn nil\n",
+		"}\n}\n}")
+}
+
+// renderUnmarshalField Render the unmarshalling of the field of an element
+func (ft *GoFormatter) renderUnmarshalField(f *childField) string {
+	field := "e." + f.field
+
+	switch {
+	case f.multiple:
+		return join("var n ", strings.TrimPrefix(f.t, "[]"), "\n",
+			"if err := d.DecodeElement(&n, &t); err != nil {\nreturn err\n}\n",
+			field, " = append(", field, ", n)\n")
+	case f.optional:
+		return join(field, " = new(", strings.TrimPrefix(f.t, "*"), ")\n",
+			"if err := d.DecodeElement(", field, ", &t); err != nil {\nreturn err\n}\n")
+	}
+	return join("if err := d.DecodeElement(&", field, ", &t); err != nil {\nreturn err\n}\n")
+}
+
+// renderUnmarshalText Render the unmarshalling of character data, appended to the last text node
+func (ft *GoFormatter) renderUnmarshalText(g *nodeGroup) string {
+	field := "e." + g.field
+
+	return join("case xml.CharData:\n",
+		"if n := len(", field, "); n > 0 {\n",
+		"if text, ok := ", field, "[n-1].(", ft.textType, "); ok {\n",
+		field, "[n-1] = text + ", ft.textType, "(t)\n",
+		"continue\n}\n}\n",
+		field, " = append(", field, ", ", ft.textType, "(t))\n")
+}
+
+// renderUnmarshalAttributes Render the unmarshalling of the attributes of the current struct
+func (ft *GoFormatter) renderUnmarshalAttributes() string {
+	if len(ft.current.attributes) == 0 {
+		return ""
+	}
+
+	cases := ""
+
+	for _, a := range ft.current.attributes {
+		field := "e." + a.field
+		cases += "case " + ft.attributeCondition(a) + ":\n"
+
+		switch {
+		case a.marshaler && a.pointer:
+			cases += join(field, " = new(", a.t, ")\n",
+				"if err := ", field, ".UnmarshalXMLAttr(attr); err != nil {\nreturn err\n}\n")
+		case a.marshaler:
+			cases += join("if err := ", field, ".UnmarshalXMLAttr(attr); err != nil {\nreturn err\n}\n")
+		case a.pointer:
+			cases += join("value := attr.Value\n", field, " = &value\n")
+		default:
+			cases += join(field, " = attr.Value\n")
+		}
+	}
+
+	return join("for _, attr := range start.Attr {\n",
+		"switch {\n", cases, "}\n}\n")
+}
+
+// renderTextType Render the type of the character data of mixed contents
+func (ft *GoFormatter) renderTextType() string {
+	return join("// ", ft.textType, " is character data of a mixed content\n",
+		"type ", ft.textType, " string\n\n")
+}
+
+// renderMarshalAttr Render the helper marshalling the attributes implementing xml.MarshalerAttr
+func (ft *GoFormatter) renderMarshalAttr() string {
+	return join("// marshalAttr appends the attribute returned by m to start, unless it is empty\n",
+		"func marshalAttr(start *xml.StartElement, name xml.Name, m xml.MarshalerAttr) error {\n",
+		"attr, err := m.MarshalXMLAttr(name)\n",
+		"if err != nil {\nreturn err\n}\n",
+		"if attr.Name.Local != \"\" {\nstart.Attr = append(start.Attr, attr)\n}\n",
+		"return nil\n}\n\n")
+}
+
+// xmlNameLiteral returns the xml.Name of an attribute as Go code
+// other prefixes than xml are written as part of the qualified name, as for elements
+func xmlNameLiteral(name string) string {
+	if strings.HasPrefix(name, "xml:") {
+		return join("xml.Name{Space: ", strconv.Quote(xmlNamespace), ", Local: ", strconv.Quote(localName(name)), "}")
+	}
+	return join("xml.Name{Local: ", strconv.Quote(name), "}")
+}
+
+// attributeCondition returns the condition matching an attribute in Go code,
+// the same way as a tag of encoding/xml, see attributeTag, an attribute sharing
+// its local name is matched by its qualified name
+func (ft *GoFormatter) attributeCondition(a *goAttribute) string {
+	if a.exact || strings.HasPrefix(a.name, "xml:") {
+		return ft.nameCondition("attr.Name", a.name, false)
+	}
+	return join("attr.Name.Local == ", strconv.Quote(localName(a.name)))
+}
+
+// nameCondition returns the condition matching a qualified name in Go code, v being its xml.Name
+// encoding/xml sets the namespace of a prefix not declared in the document to the prefix
+// itself, a prefix may also be bound to the namespace of a xmlns attribute of the DTD.
+// An unprefixed attribute has no namespace, an unprefixed element may be in the default namespace.
+func (ft *GoFormatter) nameCondition(v string, name string, element bool) string {
+	local := join(v, ".Local == ", strconv.Quote(localName(name)))
+
+	prefix := ""
+	if strings.Contains(name, ":") {
+		prefix = name[:strings.Index(name, ":")]
+	}
+
+	switch {
+	case prefix == "xml":
+		return join(v, ".Space == ", strconv.Quote(xmlNamespace), " && ", local)
+	case prefix == "" && !element:
+		return join(v, ".Space == \"\" && ", local)
+	}
+
+	space := join(v, ".Space == ", strconv.Quote(prefix))
+	if uri, ok := ft.namespaces[prefix]; ok {
+		space = join("(", space, " || ", v, ".Space == ", strconv.Quote(uri), ")")
+	}
+	return join(local, " && ", space)
+}
+
+// isPrefixed tells if an element or an attribute name has a prefix other than xml
+// prefixed names are matched by their local name in any namespace and
+// written with their qualified name, encoding/xml does not keep prefixes.
+func isPrefixed(name string) bool {
+	i := strings.Index(name, ":")
+	return i > 0 && name[:i] != "xml"
+}
+
+// localName returns a name without its prefix
+func localName(name string) string {
+	return name[strings.Index(name, ":")+1:]
+}
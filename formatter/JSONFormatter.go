@@ -0,0 +1,64 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package formatter
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/blefort/DTDParser/DTD"
+	"go.uber.org/zap"
+)
+
+// JSONFormatter renders the schema of a DTD as JSON
+type JSONFormatter struct {
+	delimitter string
+	log        *zap.SugaredLogger
+}
+
+// NewJSONFormatter instantiate new JSONFormatter struct
+func NewJSONFormatter(log *zap.SugaredLogger) *JSONFormatter {
+	var f JSONFormatter
+	f.delimitter = "\t"
+	f.log = log
+	return &f
+}
+
+// Render Render a DTD schema
+// each block holds its kind, see DTD.KindName
+func (ft *JSONFormatter) Render(schema *DTD.Schema, path string) error {
+	var sb strings.Builder
+
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", ft.delimitter)
+
+	if err := enc.Encode(schema); err != nil {
+		return err
+	}
+
+	return ft.writeToFile(path, sb.String())
+}
+
+// writeToFile write to a JSON file
+func (ft *JSONFormatter) writeToFile(filepath string, s string) error {
+	f, err := os.OpenFile(filepath, os.O_APPEND|os.O_WRONLY, 0700)
+
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.WriteString(f, s)
+
+	if err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
@@ -18,7 +18,7 @@ type iDTDFormatter interface {
 
 // SetFormatter Setter for formatter
 func AvailaibleFormatters() []string {
-	formatters := []string{"DTD", "go"}
+	formatters := []string{"DTD", "go", "json"}
 	return formatters
 }
 
@@ -30,11 +30,3 @@ func join(strs ...string) string {
 	}
 	return sb.String()
 }
-
-// printQuoted print the value with double quote if not empty
-func renderQuoted(s string) string {
-	if s == "" {
-		return ""
-	}
-	return "\"" + s + "\""
-}
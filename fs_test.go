@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	DTDParser "github.com/blefort/DTDParser/parser"
+)
+
+// TestParseReader Test parsing a DTD from a reader
+func TestParseReader(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	r := strings.NewReader("<!ELEMENT a (b)*>\n<!ELEMENT b EMPTY>")
+
+	if err := p.ParseReader("inline.dtd", r); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	t.Run("Check collection", checkIntValue(len(p.Collection), 2, p.Collection, nil))
+	t.Run("Check filepath", checkStrValue(p.Filepath, "inline.dtd", p, nil))
+}
+
+// TestParseFS Test parsing a DTD and its external references from a fs.FS
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dtd/main.dtd":     {Data: []byte("<!ENTITY % ext SYSTEM \"mods/ext.ent\">%ext;\n<!ELEMENT a EMPTY>")},
+		"dtd/mods/ext.ent": {Data: []byte("<!ELEMENT b EMPTY>")},
+		"dtd/broken.dtd":   {Data: []byte("<!ENTITY % ext SYSTEM \"missing.ent\">")},
+	}
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	if err := p.Parse("dtd/main.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	t.Run("Check collection", checkIntValue(len(p.Collection), 3, p.Collection, nil))
+
+	var extErr *DTDParser.ExternalReferenceError
+
+	p = newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	if err := p.Parse("dtd/broken.dtd"); !errors.As(err, &extErr) {
+		t.Fatalf("Expected an external reference error, got '%v'", err)
+	}
+}
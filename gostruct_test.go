@@ -0,0 +1,479 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderGo Helper to render a DTD as Go structs, returns the generated code
+// with spaces collapsed. The generated package must pass go vet.
+func renderGo(t *testing.T, src string) string {
+	dir := renderGoPackage(t, src)
+	code, err := os.ReadFile(filepath.Join(dir, "structs.go"))
+
+	if err != nil {
+		t.Fatalf("Reading Go structs failed: %v", err)
+	}
+	return strings.Join(strings.Fields(string(code)), " ")
+}
+
+// renderGoPackage Helper to render a DTD as the Go package doc of a temporary module
+// the generated package is checked with go vet, the directory of the module is returned
+func renderGoPackage(t *testing.T, src string) string {
+	dir := t.TempDir()
+	p := newParser(dir)
+	p.Package = "doc"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.SetFormatter("go"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Render(""); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module doc\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runGo(t, dir, "vet", ".")
+	return dir
+}
+
+// runGo Helper to run the go command in dir
+func runGo(t *testing.T, dir string, args ...string) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// checkGo Helper to check that generated code holds a declaration
+func checkGo(code string, decl string) func(*testing.T) {
+	return func(t *testing.T) {
+		if !strings.Contains(code, decl) {
+			t.Errorf("'%s' not found in generated code: %s", decl, code)
+		}
+	}
+}
+
+// TestGoStructFields Test the fields generated from content models
+func TestGoStructFields(t *testing.T) {
+	code := renderGo(t, "<!ENTITY % blocks \"para | list\">\n"+
+		"<!ELEMENT section (title, (%blocks;)*, note?, (a, b)+, c)>\n"+
+		"<!ELEMENT title (#PCDATA)>\n"+
+		"<!ELEMENT para (#PCDATA)>\n"+
+		"<!ELEMENT list (list-item, list-item)>\n"+
+		"<!ELEMENT list-item (#PCDATA)>\n"+
+		"<!ELEMENT note EMPTY>\n"+
+		"<!ELEMENT a EMPTY>\n"+
+		"<!ELEMENT b EMPTY>\n")
+
+	t.Run("Check struct", checkGo(code, "type Section struct { XMLName xml.Name `xml:\"section\"`"))
+	t.Run("Check required", checkGo(code, "Title Title `xml:\"title\"`"))
+	t.Run("Check choice in a list", checkGo(code, "Content []SectionContent `xml:\"-\"` Note"))
+	t.Run("Check optional", checkGo(code, "Note *Note `xml:\"note\"`"))
+	t.Run("Check sequence in a list", checkGo(code, "A []A `xml:\"a\"` B []B `xml:\"b\"`"))
+	t.Run("Check undeclared", checkGo(code, "C string `xml:\"c\"`"))
+	t.Run("Check repeated", checkGo(code, "ListItem []ListItem `xml:\"list-item\"`"))
+	t.Run("Check type name", checkGo(code, "type ListItem struct"))
+}
+
+// TestGoStructAttributes Test the fields generated from ATTLIST declarations
+func TestGoStructAttributes(t *testing.T) {
+	code := renderGo(t, "<!ELEMENT section (title)>\n"+
+		"<!ATTLIST section id ID #REQUIRED xml:lang CDATA #IMPLIED status CDATA 'draft'>\n"+
+		"<!ATTLIST section id CDATA #IMPLIED version CDATA #FIXED \"1.0\" refs IDREFS #IMPLIED title CDATA #IMPLIED>\n"+
+		"<!ELEMENT title (#PCDATA)>\n")
+
+	t.Run("Check required", checkGo(code, "Id string `xml:\"id,attr\"`"))
+	t.Run("Check implied", checkGo(code, "XmlLang *string `xml:\"http://www.w3.org/XML/1998/namespace lang,attr,omitempty\"`"))
+	t.Run("Check default", checkGo(code, "// Status defaults to \"draft\" Status string `xml:\"status,attr,omitempty\"`"))
+	t.Run("Check fixed", checkGo(code, "// Version is fixed to SectionVersion Version string `xml:\"version,attr,omitempty\"`"))
+	t.Run("Check fixed constant", checkGo(code, "const SectionVersion = \"1.0\""))
+	t.Run("Check tokens", checkGo(code, "Refs Tokens `xml:\"refs,attr,omitempty\"`"))
+	t.Run("Check tokens type", checkGo(code, "func (t *Tokens) UnmarshalXMLAttr(attr xml.Attr) error"))
+	t.Run("Check name clash", checkGo(code, "TitleAttr *string `xml:\"title,attr,omitempty\"` Title Title `xml:\"title\"`"))
+}
+
+// TestGoStructEnumerations Test the types generated for enumerated attributes
+func TestGoStructEnumerations(t *testing.T) {
+	code := renderGo(t, "<!ENTITY % importance \"status (important|normal) #IMPLIED\">\n"+
+		"<!ELEMENT section (title)>\n"+
+		"<!ATTLIST section %importance; level (1|x-y) '1'>\n"+
+		"<!ELEMENT title (#PCDATA)>\n"+
+		"<!ATTLIST title %importance; type (a|b) #REQUIRED>\n")
+
+	t.Run("Check type", checkGo(code, "type Status string"))
+	t.Run("Check constants", checkGo(code, "StatusImportant Status = \"important\" StatusNormal Status = \"normal\""))
+	t.Run("Check valid", checkGo(code, "func (v Status) Valid() bool { switch v { case StatusImportant, StatusNormal: return true }"))
+	t.Run("Check unmarshal", checkGo(code, "func (v *Status) UnmarshalXMLAttr(attr xml.Attr) error"))
+	t.Run("Check implied", checkGo(code, "Status *Status `xml:\"status,attr,omitempty\"`"))
+	t.Run("Check default", checkGo(code, "Level Level `xml:\"level,attr,omitempty\"`"))
+	t.Run("Check value names", checkGo(code, "Level1 Level = \"1\" LevelXY Level = \"x-y\""))
+	t.Run("Check required", checkGo(code, "Type Type `xml:\"type,attr\"`"))
+	t.Run("Check shared type", checkIntValue(strings.Count(code, "type Status string"), 1, code, nil))
+}
+
+// TestGoStructMixed Test the types generated for mixed contents
+func TestGoStructMixed(t *testing.T) {
+	code := renderGo(t, "<!ELEMENT p (#PCDATA | b | i)*>\n"+
+		"<!ATTLIST p id ID #REQUIRED kind (x|y) #IMPLIED>\n"+
+		"<!ELEMENT b (#PCDATA)>\n"+
+		"<!ATTLIST b text CDATA #IMPLIED>\n"+
+		"<!ELEMENT i EMPTY>\n")
+
+	t.Run("Check chardata", checkGo(code, "TextAttr *string `xml:\"text,attr,omitempty\"` Text string `xml:\",chardata\"`"))
+	t.Run("Check content", checkGo(code, "Content []PContent `xml:\"-\"`"))
+	t.Run("Check interface", checkGo(code, "type PContent interface { isPContent() }"))
+	t.Run("Check text node", checkGo(code, "func (Text) isPContent() {}"))
+	t.Run("Check element node", checkGo(code, "func (*B) isPContent() {}"))
+	t.Run("Check text type", checkGo(code, "type Text string"))
+	t.Run("Check marshal", checkGo(code, "func (e P) MarshalXML(enc *xml.Encoder, start xml.StartElement) error"))
+	t.Run("Check marshal attribute", checkGo(code, "start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: \"id\"}, Value: e.Id})"))
+	t.Run("Check marshal text", checkGo(code, "err = enc.EncodeToken(xml.CharData(text))"))
+	t.Run("Check unmarshal", checkGo(code, "func (e *P) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error"))
+	t.Run("Check unmarshal attribute", checkGo(code, "e.Kind = new(Kind) if err := e.Kind.UnmarshalXMLAttr(attr); err != nil"))
+	t.Run("Check unmarshal element", checkGo(code, "case \"i\": n := new(I) if err := d.DecodeElement(n, &t); err != nil { return err } e.Content = append(e.Content, n)"))
+}
+
+// TestGoStructChoices Test the types generated for choice groups
+func TestGoStructChoices(t *testing.T) {
+	code := renderGo(t, "<!ELEMENT doc (title, (para | list)*, (hr | br)?, (a | b), (a | c)?)>\n"+
+		"<!ELEMENT title (#PCDATA)>\n"+
+		"<!ELEMENT para (#PCDATA)>\n"+
+		"<!ELEMENT list EMPTY>\n"+
+		"<!ELEMENT hr EMPTY>\n"+
+		"<!ELEMENT br EMPTY>\n"+
+		"<!ELEMENT a EMPTY>\n"+
+		"<!ELEMENT b EMPTY>\n"+
+		"<!ELEMENT c EMPTY>\n")
+
+	t.Run("Check fields", checkGo(code, "Title Title `xml:\"title\"` Content []DocContent `xml:\"-\"` Content2 DocContent2 `xml:\"-\"` A []A `xml:\"a\"` B *B `xml:\"b\"` C *C `xml:\"c\"`"))
+	t.Run("Check interface", checkGo(code, "type DocContent interface { isDocContent() }"))
+	t.Run("Check node", checkGo(code, "func (*List) isDocContent() {}"))
+	t.Run("Check marshal field", checkGo(code, "if err := enc.EncodeElement(e.Title, xml.StartElement{Name: xml.Name{Local: \"title\"}}); err != nil"))
+	t.Run("Check marshal single", checkGo(code, "if e.Content2 != nil { if err := enc.Encode(e.Content2); err != nil"))
+	t.Run("Check unmarshal single", checkGo(code, "case \"br\": n := new(Br) if err := d.DecodeElement(n, &t); err != nil { return err } e.Content2 = n"))
+	t.Run("Check unmarshal list", checkGo(code, "case \"a\": var n A if err := d.DecodeElement(&n, &t); err != nil { return err } e.A = append(e.A, n)"))
+	t.Run("Check unmarshal optional", checkGo(code, "case \"b\": e.B = new(B) if err := d.DecodeElement(e.B, &t); err != nil"))
+}
+
+// goRoundTripTest is the test of the package generated by TestGoStructRoundTrip
+const goRoundTripTest = `package doc
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const sample = "<doc status=\"final\"><title>T</title><para>a <em>b</em> c<code></code>d</para>" +
+	"<list><item>1</item><item>2</item></list><para>e</para><note>n</note>" +
+	"<steps><x></x><a></a><x></x><b></b></steps></doc>"
+
+func decode(t *testing.T) Doc {
+	var d Doc
+	if err := xml.Unmarshal([]byte(sample), &d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestRoundTrip(t *testing.T) {
+	out, err := xml.Marshal(decode(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != sample {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestChoiceOrder(t *testing.T) {
+	d := decode(t)
+	if len(d.Content) != 3 {
+		t.Fatalf("got %#v", d.Content)
+	}
+	_, first := d.Content[0].(*Para)
+	_, second := d.Content[1].(*List)
+	_, third := d.Content[2].(*Para)
+	if !first || !second || !third {
+		t.Errorf("got %#v", d.Content)
+	}
+}
+
+func TestSequenceOrder(t *testing.T) {
+	c := decode(t).Steps.Content
+	if len(c) != 4 {
+		t.Fatalf("got %#v", c)
+	}
+	_, first := c[0].(*X)
+	_, second := c[1].(*A)
+	_, third := c[2].(*X)
+	_, fourth := c[3].(*B)
+	if !first || !second || !third || !fourth {
+		t.Errorf("got %#v", c)
+	}
+}
+
+func TestMixedOrder(t *testing.T) {
+	p := decode(t).Content[0].(*Para)
+	if len(p.Content) != 5 || p.Content[0] != Text("a ") || p.Content[2] != Text(" c") || p.Content[4] != Text("d") {
+		t.Fatalf("got %#v", p.Content)
+	}
+	if _, ok := p.Content[1].(*Em); !ok {
+		t.Errorf("got %#v", p.Content[1])
+	}
+	if _, ok := p.Content[3].(*Code); !ok {
+		t.Errorf("got %#v", p.Content[3])
+	}
+}
+
+func TestEnumRejected(t *testing.T) {
+	var d Doc
+	if err := xml.Unmarshal([]byte("<doc status=\"bogus\"><title>T</title></doc>"), &d); err == nil {
+		t.Errorf("unknown value accepted: %#v", d)
+	}
+	status := Status("bogus")
+	if _, err := xml.Marshal(Doc{Status: &status}); err == nil {
+		t.Errorf("unknown value marshalled")
+	}
+}
+`
+
+// TestGoStructRoundTrip Test the generated types decode and encode documents
+// keeping the order of choices, of repeated sequences holding choices and of mixed contents
+func TestGoStructRoundTrip(t *testing.T) {
+	dir := renderGoPackage(t, "<!ELEMENT doc (title, (para | list)*, note?, steps?)>\n"+
+		"<!ATTLIST doc status (draft|final) #IMPLIED version CDATA #FIXED \"1.0\">\n"+
+		"<!ELEMENT title (#PCDATA)>\n"+
+		"<!ELEMENT para (#PCDATA | em | code)*>\n"+
+		"<!ELEMENT em (#PCDATA)>\n"+
+		"<!ELEMENT code EMPTY>\n"+
+		"<!ELEMENT list (item+)>\n"+
+		"<!ELEMENT item (#PCDATA)>\n"+
+		"<!ELEMENT note (#PCDATA)>\n"+
+		"<!ELEMENT steps (x, (a | b))*>\n"+
+		"<!ELEMENT x EMPTY>\n"+
+		"<!ELEMENT a EMPTY>\n"+
+		"<!ELEMENT b EMPTY>\n")
+
+	if err := os.WriteFile(filepath.Join(dir, "doc_test.go"), []byte(goRoundTripTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runGo(t, dir, "test", ".")
+}
+
+// goPrefixedTest is the test of the package generated by TestGoStructPrefixed
+const goPrefixedTest = `package doc
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const sample = "<rec><dc:title>T</dc:title><dc:creator>A</dc:creator><dc:creator>B</dc:creator>" +
+	"<dc:date>2020</dc:date><note>a <dc:ref></dc:ref></note></rec>"
+
+func TestRoundTrip(t *testing.T) {
+	var r Rec
+	if err := xml.Unmarshal([]byte(sample), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.DcTitle.Text != "T" || len(r.DcCreator) != 2 {
+		t.Fatalf("got %#v", r)
+	}
+	out, err := xml.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != sample {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	var title DcTitle
+	if err := xml.Unmarshal([]byte("<dc:title xmlns:dc=\"http://purl.org/dc/elements/1.1/\">T</dc:title>"), &title); err != nil {
+		t.Fatal(err)
+	}
+	out, err := xml.Marshal(title)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "<dc:title>T</dc:title>" {
+		t.Errorf("got %s", out)
+	}
+}
+`
+
+// TestGoStructPrefixed Test prefixed elements are matched by their local name
+// and written with their qualified name
+func TestGoStructPrefixed(t *testing.T) {
+	src := "<!ELEMENT rec (dc:title, dc:creator*, (dc:date | year)?, note)>\n" +
+		"<!ELEMENT dc:title (#PCDATA)>\n" +
+		"<!ELEMENT dc:creator (#PCDATA)>\n" +
+		"<!ELEMENT dc:date (#PCDATA)>\n" +
+		"<!ELEMENT year (#PCDATA)>\n" +
+		"<!ELEMENT note (#PCDATA | dc:ref)*>\n" +
+		"<!ELEMENT dc:ref EMPTY>\n"
+
+	code := renderGo(t, src)
+
+	t.Run("Check name tag", checkGo(code, "type DcTitle struct { XMLName xml.Name `xml:\"title\"`"))
+	t.Run("Check field tag", checkGo(code, "DcCreator []DcCreator `xml:\"creator\"`"))
+	t.Run("Check marshal", checkGo(code, "func (e DcTitle) MarshalXML(enc *xml.Encoder, start xml.StartElement) error { type element DcTitle start = xml.StartElement{Name: xml.Name{Local: \"dc:title\"}}"))
+	t.Run("Check marshal field", checkGo(code, "enc.EncodeElement(e.DcCreator, xml.StartElement{Name: xml.Name{Local: \"dc:creator\"}})"))
+	t.Run("Check unmarshal field", checkGo(code, "case \"creator\": var n DcCreator"))
+
+	dir := renderGoPackage(t, src)
+
+	if err := os.WriteFile(filepath.Join(dir, "doc_test.go"), []byte(goPrefixedTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runGo(t, dir, "test", ".")
+}
+
+// goPrefixedAttributesTest is the test of the package generated by TestGoStructPrefixedAttributes
+const goPrefixedAttributesTest = `package doc
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const sample = "<doc href=\"a\" xlink:href=\"b\" xlink:type=\"simple\">T</doc>"
+
+func TestRoundTrip(t *testing.T) {
+	var d Doc
+	if err := xml.Unmarshal([]byte(sample), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Href == nil || *d.Href != "a" || d.XlinkHref == nil || *d.XlinkHref != "b" {
+		t.Fatalf("got %#v", d)
+	}
+	out, err := xml.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != sample {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	var d Doc
+	src := "<doc xmlns:xlink=\"http://www.w3.org/1999/xlink\" xlink:href=\"b\" href=\"a\"></doc>"
+	if err := xml.Unmarshal([]byte(src), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Href == nil || *d.Href != "a" || d.XlinkHref == nil || *d.XlinkHref != "b" {
+		t.Errorf("got %#v", d)
+	}
+}
+`
+
+// TestGoStructPrefixedAttributes Test prefixed attributes are written with their qualified
+// name, and matched by their namespace when their local name is shared
+func TestGoStructPrefixedAttributes(t *testing.T) {
+	src := "<!ELEMENT doc (#PCDATA)>\n" +
+		"<!ATTLIST doc href CDATA #IMPLIED xlink:href CDATA #IMPLIED xlink:type CDATA #IMPLIED\n" +
+		"  xmlns:xlink CDATA #FIXED \"http://www.w3.org/1999/xlink\">\n"
+
+	code := renderGo(t, src)
+
+	t.Run("Check shared tags", checkGo(code, "Href *string `xml:\"-\"` XlinkHref *string `xml:\"-\"`"))
+	t.Run("Check tag", checkGo(code, "XlinkType *string `xml:\"type,attr,omitempty\"`"))
+	t.Run("Check marshal", checkGo(code, "start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: \"xlink:href\"}, Value: *e.XlinkHref})"))
+	t.Run("Check unmarshal unprefixed", checkGo(code, "case attr.Name.Space == \"\" && attr.Name.Local == \"href\":"))
+	t.Run("Check unmarshal prefixed", checkGo(code, "case attr.Name.Local == \"href\" && (attr.Name.Space == \"xlink\" || attr.Name.Space == \"http://www.w3.org/1999/xlink\"):"))
+
+	dir := renderGoPackage(t, src)
+
+	if err := os.WriteFile(filepath.Join(dir, "doc_test.go"), []byte(goPrefixedAttributesTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runGo(t, dir, "test", ".")
+}
+
+// goSharedNamesTest is the test of the package generated by TestGoStructSharedNames
+const goSharedNamesTest = `package doc
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const sample = "<rec><title>A</title><dc:title>B</dc:title><dc:note></dc:note><note></note></rec>"
+
+func TestRoundTrip(t *testing.T) {
+	var r Rec
+	if err := xml.Unmarshal([]byte(sample), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Title.Text != "A" || r.DcTitle.Text != "B" || len(r.Content) != 2 {
+		t.Fatalf("got %#v", r)
+	}
+	if _, ok := r.Content[0].(*DcNote); !ok {
+		t.Errorf("got %#v", r.Content)
+	}
+	out, err := xml.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != sample {
+		t.Errorf("got %s", out)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	var r Rec
+	src := "<rec xmlns:dc=\"http://purl.org/dc/elements/1.1/\"><dc:title>B</dc:title><title>A</title></rec>"
+	if err := xml.Unmarshal([]byte(src), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Title.Text != "A" || r.DcTitle.Text != "B" {
+		t.Errorf("got %#v", r)
+	}
+}
+`
+
+// TestGoStructSharedNames Test elements sharing their local name are matched by their namespace
+func TestGoStructSharedNames(t *testing.T) {
+	src := "<!ELEMENT rec (title, dc:title, (note | dc:note)*)>\n" +
+		"<!ATTLIST rec xmlns:dc CDATA #FIXED \"http://purl.org/dc/elements/1.1/\">\n" +
+		"<!ELEMENT title (#PCDATA)>\n" +
+		"<!ELEMENT dc:title (#PCDATA)>\n" +
+		"<!ELEMENT note EMPTY>\n" +
+		"<!ELEMENT dc:note EMPTY>\n"
+
+	code := renderGo(t, src)
+
+	t.Run("Check tags", checkGo(code, "Title Title `xml:\"-\"` DcTitle DcTitle `xml:\"-\"`"))
+	t.Run("Check unmarshal", checkGo(code, "case \"title\": switch { case t.Name.Local == \"title\" && t.Name.Space == \"\": if err := d.DecodeElement(&e.Title, &t); err != nil"))
+	t.Run("Check unmarshal prefixed", checkGo(code, "case t.Name.Local == \"title\" && (t.Name.Space == \"dc\" || t.Name.Space == \"http://purl.org/dc/elements/1.1/\"): if err := d.DecodeElement(&e.DcTitle, &t); err != nil"))
+
+	dir := renderGoPackage(t, src)
+
+	if err := os.WriteFile(filepath.Join(dir, "doc_test.go"), []byte(goSharedNamesTest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runGo(t, dir, "test", ".")
+}
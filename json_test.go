@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// TestJSON Test the JSON round trip of a schema
+func TestJSON(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.dtd": {Data: []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+			"<!-- main -->\n" +
+			"<!ENTITY % mod SYSTEM \"mod.ent\">\n" +
+			"%mod;\n" +
+			"<!ELEMENT doc (title, (para | list)*)>\n" +
+			"<!ATTLIST doc status (draft | final) 'draft' id ID #REQUIRED>\n" +
+			"<?xml-stylesheet href=\"style.xsl\"?>\n")},
+		"mod.ent": {Data: []byte("<![INCLUDE[\n<!ELEMENT title (#PCDATA)>\n]]>\n" +
+			"<!ENTITY copy '(c)'>\n" +
+			"<!NOTATION png SYSTEM \"image/png\">\n")},
+	}
+
+	dir := t.TempDir()
+	p := newParser(dir)
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	schema, err := p.ParseSchema("main.dtd")
+
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	data, err := json.Marshal(schema)
+
+	if err != nil {
+		t.Fatalf("Marshalling failed: %v", err)
+	}
+
+	t.Run("Check kind", checkBoolValue(strings.Contains(string(data), `"kind":"conditional"`), true, string(data), nil))
+
+	var decoded DTD.Schema
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshalling failed: %v", err)
+	}
+
+	t.Run("Check round trip", checkBoolValue(reflect.DeepEqual(&decoded, schema), true, &decoded, schema))
+	t.Run("Check index", checkStrValue(decoded.Elements["title"].Value, " (#PCDATA)", decoded.Elements, nil))
+
+	// -format json
+	if err := p.SetFormatter("json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Render(""); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(dir, "main.json"))
+
+	if err != nil {
+		t.Fatalf("Reading JSON failed: %v", err)
+	}
+
+	var rendered DTD.Schema
+
+	if err := json.Unmarshal(written, &rendered); err != nil {
+		t.Fatalf("Unmarshalling the rendered file failed: %v", err)
+	}
+
+	t.Run("Check rendered file", checkBoolValue(reflect.DeepEqual(&rendered, schema), true, &rendered, schema))
+}
+
+// TestUnmarshalBlocks Test the decoding of blocks by kind
+func TestUnmarshalBlocks(t *testing.T) {
+	blocks, err := DTD.UnmarshalBlocks([]byte(`[{"kind":"element","name":"b","value":" EMPTY"},{"kind":"comment","value":" c "}]`))
+
+	if err != nil {
+		t.Fatalf("Unmarshalling failed: %v", err)
+	}
+
+	t.Run("Check element", checkStrValue(blocks[0].(*DTD.Element).Name, "b", blocks[0], nil))
+	t.Run("Check comment", checkIntValue(blocks[1].Kind(), DTD.COMMENT, blocks[1], nil))
+
+	_, err = DTD.UnmarshalBlock([]byte(`{"kind":"doctype"}`))
+	t.Run("Check unknown kind", checkBoolValue(err != nil, true, err, nil))
+}
+
+// TestJSONGolden Test the JSON format against tests/golden.json
+// run go test -run TestJSONGolden -update to write it again
+func TestJSONGolden(t *testing.T) {
+	dir := t.TempDir()
+	p := newParser(dir)
+
+	if err := p.Parse("tests/golden.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.SetFormatter("json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Render(""); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(dir, "golden.json"))
+
+	if err != nil {
+		t.Fatalf("Reading JSON failed: %v", err)
+	}
+
+	if update {
+		if err := os.WriteFile("tests/golden.json", written, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden, err := os.ReadFile("tests/golden.json")
+
+	if err != nil {
+		t.Fatalf("Reading golden file failed: %v", err)
+	}
+
+	t.Run("Check golden file", checkStrValue(string(written), string(golden), nil, nil))
+}
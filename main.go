@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	DTDParser "github.com/blefort/DTDParser/parser"
@@ -18,20 +19,35 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// catalogFlag holds the catalogs given with -catalog
+type catalogFlag []string
+
+func (c *catalogFlag) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *catalogFlag) Set(s string) error {
+	*c = append(*c, s)
+	return nil
+}
+
 // main func
 func main() {
 
 	var level zap.AtomicLevel
+	var catalogs catalogFlag
 
 	// Input file
 	DTDFullPath := flag.String("DTD", "", "Path to the DTD")
 	DTDOutput := flag.String("output", "", "Output path to re-generate DTD")
 	GoStructOutput := flag.String("type", "", "Output path to generate go structs")
-	formatter := flag.String("format", "go", "Choose the output format (go, DTD) ")
+	formatter := flag.String("format", "go", "Choose the output format (go, DTD, json) ")
 	packageName := flag.String("package", "", "Package name")
 	overwrite := flag.Bool("overwrite", false, "Overwrite output file")
 	verbosity := flag.String("verbosity", "", "Verbose v, vv or vvv")
 	ignoreExtRef := flag.Bool("ignore-external-dtd", false, "Do not process external DTD")
+	encoding := flag.String("encoding", "", "Force the encoding of the DTD (UTF-8, UTF-16, ISO-8859-1, US-ASCII, windows-1252)")
+	flag.Var(&catalogs, "catalog", "OASIS XML Catalog used to resolve external DTD, can be repeated")
 
 	flag.Parse()
 
@@ -104,6 +120,8 @@ func main() {
 	log.Warnf(" - Option Formater: %s", *formatter)
 	log.Warnf(" - Option Verbosity: %s", *verbosity)
 	log.Warnf(" - Option ignore external references: %t", *ignoreExtRef)
+	log.Warnf(" - Option catalogs: %s", catalogs.String())
+	log.Warnf(" - Option encoding: %s", *encoding)
 
 	log.Warnf("")
 
@@ -114,9 +132,24 @@ func main() {
 	// New parser
 	p := DTDParser.NewDTDParser(log)
 	p.IgnoreExtRefIssue = *ignoreExtRef
-	p.SetFormatter(*formatter)
 	p.Package = *packageName
 
+	if err := p.SetFormatter(*formatter); err != nil {
+		log.Fatal(err)
+	}
+
+	if *encoding != "" {
+		if err := p.SetEncoding(*encoding); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, c := range catalogs {
+		if err := p.AddCatalog(c); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if *overwrite {
 		p.Overwrite = true
 	}
@@ -145,9 +178,14 @@ func main() {
 
 	// Parse & render
 	t1 := time.Now().Unix()
-	p.Parse(DTDFullPathAbs)
+	if err := p.Parse(DTDFullPathAbs); err != nil {
+		log.Fatal(err)
+	}
 	t2 := time.Now().Unix()
 	diff := t2 - t1
 	log.Warnf(fmt.Sprintf("Parsed in %d ms", diff))
-	p.Render("")
+
+	if err := p.Render(""); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/blefort/DTDParser/DTD"
@@ -17,32 +18,28 @@ func loadNotationTests(file string) []DTD.Notation {
 func TestParseNotationBlock(t *testing.T) {
 	// - parse the DTD test
 	// - compare it to data stored in a json file
-	// - render it in the tmp dir
+	// - render it in a temporary dir
 	t.Log("Start tests on 'tests/notation.dtd'")
-	testNotationDTD(t, "tests/notation.dtd", true)
+	tmp := t.TempDir()
+	testNotationDTD(t, "tests/notation.dtd", tmp)
 
 	// - load the generated DTD
 	// - compare it to data stored in a json file
-	//	t.Log("Start tests on 'tmp/notation.dtd'")
-	testNotationDTD(t, "tmp/notation.dtd", false)
+	//	t.Log("Start tests on the rendered 'notation.dtd'")
+	testNotationDTD(t, filepath.Join(tmp, "notation.dtd"), t.TempDir())
 }
 
 // testCommentDTD Main func holding tests
-func testNotationDTD(t *testing.T, path string, recreate bool) {
+func testNotationDTD(t *testing.T, path string, dir string) {
 	var tests []DTD.Notation
 
 	// New parser
-	var dir string
-
-	if recreate {
-		dir = "tmp"
-	} else {
-		dir = "tmp2"
-	}
 
 	// New parser
 	p := newParser(dir)
-	p.Parse(path)
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
 
 	tests = loadNotationTests("tests/notation.json")
 
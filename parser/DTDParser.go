@@ -10,7 +10,10 @@ package DTDParser
 // https://bp.Log.gopheracademy.com/advent-2014/parsers-lexers/
 //
 import (
-	"io/ioutil"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -19,6 +22,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/blefort/DTDParser/DTD"
+	"github.com/blefort/DTDParser/catalog"
+	"github.com/blefort/DTDParser/charset"
 	"github.com/blefort/DTDParser/formatter"
 
 	"github.com/blefort/DTDParser/scanner"
@@ -30,9 +35,17 @@ type Parser struct {
 	WithComments      bool
 	IgnoreExtRefIssue bool
 	Filepath          string
+	Encoding          string // encoding detected in the DTD
 	Collection        []DTD.IDTDBlock
+	Expanded          []DTD.IDTDBlock
 	parsers           []Parser
+	externals         map[*DTD.Entity]*Parser
 	filepaths         *[]string
+	fsys              fs.FS
+	resolver          EntityResolver
+	catalogs          []*catalog.Catalog
+	encoding          string
+	errors            scanner.ErrorList
 	formatter         string
 	outputDirPath     string
 	outputStructPath  string
@@ -49,15 +62,70 @@ func NewDTDParser(Log *zap.SugaredLogger) *Parser {
 }
 
 // SetFormatter Setter for formatter
-func (p *Parser) SetFormatter(s string) {
+func (p *Parser) SetFormatter(s string) error {
 
 	for _, f := range formatter.AvailaibleFormatters() {
 		if f == s {
 			p.formatter = s
-			return
+			return nil
 		}
 	}
-	panic("Formatter " + s + " is not defined")
+	return &UnknownFormatterError{Formatter: s}
+}
+
+// SetFS set the file system used to read the DTD and its external references
+// paths given to Parse are then relative to the root of fsys
+func (p *Parser) SetFS(fsys fs.FS) {
+	p.fsys = fsys
+}
+
+// SetEncoding force the encoding of the DTD and of its external references
+// by default, the encoding is detected with the byte order mark and the text declaration
+func (p *Parser) SetEncoding(name string) error {
+	enc, err := charset.Canonical(name)
+
+	if err != nil {
+		return err
+	}
+
+	p.encoding = enc
+	return nil
+}
+
+// SetResolver set the resolver used to find external entities
+// by default, system IDs are resolved relatively to the declaring DTD
+func (p *Parser) SetResolver(r EntityResolver) {
+	p.resolver = r
+}
+
+// AddCatalog load an OASIS XML Catalog used to resolve external entities
+// catalogs are consulted in the order they are added, before the resolver
+// the catalog is read from the file system set with SetFS, if any
+func (p *Parser) AddCatalog(path string) error {
+	c, err := catalog.LoadFS(p.fsys, path)
+
+	if err != nil {
+		return err
+	}
+
+	p.Log.Infof("Catalog '%s' loaded, %d entries", path, len(c.Entries))
+	p.catalogs = append(p.catalogs, c)
+	return nil
+}
+
+// entityResolver returns the resolver of external entities
+func (p *Parser) entityResolver() EntityResolver {
+	var resolver EntityResolver = &FileResolver{FS: p.fsys}
+
+	if p.resolver != nil {
+		resolver = p.resolver
+	}
+
+	if len(p.catalogs) > 0 {
+		return &CatalogResolver{Catalogs: p.catalogs, Fallback: resolver, FS: p.fsys}
+	}
+
+	return resolver
 }
 
 // SetOutputPath set the output path of the DTD
@@ -72,15 +140,17 @@ func (p *Parser) SetStructPath(s string) {
 }
 
 // createOutputFile Create a DTD output file
-func (p *Parser) createOutputFile(filepath string, overwrite bool) {
+func (p *Parser) createOutputFile(filepath string, overwrite bool) error {
 
 	exists := p.fileExists(filepath)
 
 	if exists && overwrite {
-		p.removeFile(filepath)
+		if err := p.removeFile(filepath); err != nil {
+			return err
+		}
 	} else if exists {
 		p.Log.Debugf("createOutputFile '%s' exists and can't be overwritten", filepath)
-		return
+		return &OutputExistsError{Path: filepath}
 	}
 
 	p.Log.Debugf("createOutputFile '%s', truncate will be '%t'", filepath, overwrite)
@@ -88,10 +158,10 @@ func (p *Parser) createOutputFile(filepath string, overwrite bool) {
 	f, err := os.Create(filepath)
 
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	f.Close()
+	return f.Close()
 }
 
 // fileExists Test if a file exists
@@ -104,50 +174,70 @@ func (p *Parser) fileExists(path string) bool {
 }
 
 // removeFile Empty content of a file
-func (p *Parser) removeFile(filepath string) {
+func (p *Parser) removeFile(filepath string) error {
 	p.Log.Debugf("Remove '%s'", filepath)
-	err := os.Remove(filepath)
-	if err != nil {
-		p.Log.Fatal(err)
+	return os.Remove(filepath)
+}
+
+// open Open a file from the file system set with SetFS or from the OS
+func (p *Parser) open(name string) (io.ReadCloser, error) {
+	if p.fsys != nil {
+		return p.fsys.Open(name)
 	}
+	return os.Open(name)
 }
 
 // Parse Parse a DTD using its path
-func (p *Parser) Parse(filePath string) {
+// Malformed blocks are skipped and their syntax errors, including the ones of
+// external DTDs, are returned in a scanner.ErrorList once the DTD is parsed.
+// A missing external reference stops the parsing
+func (p *Parser) Parse(filePath string) error {
+
+	f, err := p.open(filePath)
+
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return p.ParseReader(filePath, f)
+}
+
+// ParseReader Parse a DTD read from r
+// name identifies the DTD, external references are resolved relatively to it
+func (p *Parser) ParseReader(name string, r io.Reader) error {
 	var filespaths []string
 
-	p.Log.Infof("parsing '%s'", filePath)
+	p.Log.Infof("parsing '%s'", name)
 
 	if p.filepaths == nil {
 		p.filepaths = &filespaths
 		p.Log.Debugf("Parser filepaths was nil")
 	}
-	p.Filepath = filePath
+	p.Filepath = name
 
-	// Open file
-	filebuffer, err := ioutil.ReadFile(p.Filepath)
+	filebuffer, err := io.ReadAll(r)
 
 	if err != nil {
-		p.Log.Fatal(err)
+		return err
 	}
 
-	// Fileinfo
-	stat, err := os.Stat(filePath)
-	if err != nil {
+	p.Log.Debugf("Parsing '%s', %d bytes", p.Filepath, len(filebuffer))
 
-		p.Log.Fatal(err)
-	}
+	*p.filepaths = append(*p.filepaths, p.Filepath)
 
-	bytes := stat.Size()
-	p.Log.Debugf("Parsing '%s', %d bytes", p.Filepath, bytes)
+	// transcode to UTF-8 before reading runes
+	inputdata, encoding, err := charset.Decode(filebuffer, p.encoding)
 
-	*p.filepaths = append(*p.filepaths, p.Filepath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
 
-	// use  bufio to read file rune by rune
-	inputdata := string(filebuffer)
+	p.Encoding = encoding
+	p.Log.Debugf("Encoding of '%s' is %s", p.Filepath, p.Encoding)
 
 	//p.Log.Debugf("File content is: %s", inputdata)
-	scanner := scanner.NewScanner(filePath, inputdata, p.Log)
+	scanner := scanner.NewReaderScanner(name, strings.NewReader(inputdata), p.Log)
 
 	// not sure if this is correct methodology
 	// I tried to separate the DTD Scanner from the parser
@@ -155,95 +245,147 @@ func (p *Parser) Parse(filePath string) {
 	// will put in a collection.
 	for scanner.NextBlock() {
 
-		DTDBlock, extraWords, err := scanner.Scan()
+		DTDBlock, err := scanner.Scan()
 
 		if err != nil {
-			p.Log.Debugf("%v", err)
-			continue
+			return err
 		}
 
-		for _, word := range extraWords {
-			entityName := strings.Trim(word.Read(), "%; ")
-			p.Log.Warnf("Exporting entity: '" + entityName + "'")
-			p.SetExportEntity(entityName)
+		// keep the syntax errors in the order of the DTD
+		p.errors = append(p.errors, scanner.Errors...)
+		scanner.Errors = nil
+
+		if DTDBlock == nil {
+			continue
 		}
 
 		p.Collection = append(p.Collection, DTDBlock)
 
-		if DTD.IsEntityType(DTDBlock) {
-			p.parseExternalEntity(DTDBlock.(*DTD.Entity))
+		err = p.parseExternalEntities([]DTD.IDTDBlock{DTDBlock})
+		if err != nil {
+			return err
 		}
 
 	}
 	p.Log.Infof("%d blocks found in DTD '%s'", len(p.Collection), p.Filepath)
+	return p.errors.Err()
+}
+
+// parseExternalEntities Parse the external DTD references declared in blocks
+// and in their conditional sections
+func (p *Parser) parseExternalEntities(blocks []DTD.IDTDBlock) error {
+	for _, block := range blocks {
+		var err error
+
+		switch b := block.(type) {
+		case *DTD.Entity:
+			err = p.parseExternalEntity(b)
+		case *DTD.ConditionalSection:
+			err = p.parseExternalEntities(b.Blocks)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // parseExternalEntity Parse an external DTD reference declared in an entity
-func (p *Parser) parseExternalEntity(e *DTD.Entity) {
+// only external parameter entities hold declarations, general entities are not read
+func (p *Parser) parseExternalEntity(e *DTD.Entity) error {
 
 	p.Log.Debugf("Check entity '%s' for external reference", e.Name)
 
-	if !e.IsExternal {
+	if !e.IsExternal || !e.Parameter {
 		p.Log.Debugf("No external DTD in entity %s", e.Name)
-		return
+		return nil
 	}
 
-	base := filepath.Base(p.Filepath)
-	parentDir := filepath.Dir(p.Filepath)
-	path := parentDir + "/" + e.Url
-
-	errMsg := "External DTD '" + e.Url + "' not found, declared in '" + base + "', entity '" + e.Name
+	var publicID string
 
-	if _, err := os.Stat(path); os.IsNotExist(err) && !p.IgnoreExtRefIssue {
-		p.Log.Fatal(errMsg)
+	if e.Public {
+		publicID = e.Value
 	}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) && p.IgnoreExtRefIssue {
-		p.Log.Warnf(errMsg)
-		return
+	f, path, err := p.entityResolver().Resolve(publicID, e.Url, p.Filepath)
+
+	if err != nil {
+		extErr := &ExternalReferenceError{
+			File:   p.Filepath,
+			Line:   e.Position.Line,
+			Column: e.Position.Column,
+			Entity: e.Name,
+			Url:    e.Url,
+			Err:    err,
+		}
+
+		if !p.IgnoreExtRefIssue {
+			return extErr
+		}
+
+		p.Log.Warnf("%v", extErr)
+		return nil
 	}
+	defer f.Close()
 
 	p.Log.Warnf("*** New parser *** for external entity %s", path)
 
-	extP := NewDTDParser(p.Log)
-	extP.outputDirPath = p.outputDirPath
+	extP := p.newChildParser()
 	extP.filepaths = p.filepaths
-	extP.WithComments = p.WithComments
-	extP.IgnoreExtRefIssue = p.IgnoreExtRefIssue
-	extP.Overwrite = p.Overwrite
-	extP.Parse(path)
+	extP.encoding = p.encoding
+
+	if err := extP.ParseReader(path, f); err != nil {
+		var syntaxErrs scanner.ErrorList
+
+		if !errors.As(err, &syntaxErrs) {
+			return err
+		}
+		p.errors = append(p.errors, syntaxErrs...)
+	}
 
 	p.Log.Warnf("*** /end of New parser %s", path)
 	p.parsers = append(p.parsers, *extP)
 
+	if p.externals == nil {
+		p.externals = make(map[*DTD.Entity]*Parser)
+	}
+	p.externals[e] = extP
+
+	return nil
 }
 
-// SetExportEntity Mark an entity block are exported in the collection
-func (p *Parser) SetExportEntity(name string) {
-	for _, block := range p.Collection {
-		if block.GetName() == name {
-			p.Log.Debugf("Marking %s as exported", name)
-			block.SetExported(true)
-			return
-		}
-	}
-	p.Log.Warnf("could not find ", name, " in the current collection")
+// newChildParser returns a new parser sharing the configuration of p
+func (p *Parser) newChildParser() *Parser {
+	child := NewDTDParser(p.Log)
+	child.outputDirPath = p.outputDirPath
+	child.WithComments = p.WithComments
+	child.IgnoreExtRefIssue = p.IgnoreExtRefIssue
+	child.Overwrite = p.Overwrite
+	child.fsys = p.fsys
+	child.resolver = p.resolver
+	child.catalogs = p.catalogs
+	return child
 }
 
 // RenderDTD Render a collection to a or a set of DTD files
-func (p *Parser) Render(parentDir string) {
+func (p *Parser) Render(parentDir string) error {
 
 	switch p.formatter {
 	case "DTD":
-		p.renderDTD(parentDir)
+		return p.renderDTD(parentDir)
 
 	case "go":
-		p.renderGoStructs(parentDir, p.Package)
+		return p.renderGoStructs(parentDir, p.Package)
+
+	case "json":
+		return p.renderJSON(parentDir)
 	}
+	return &UnknownFormatterError{Formatter: p.formatter}
 }
 
 // RenderDTD Render a collection to a or a set of DTD files
-func (p *Parser) renderDTD(parentDir string) {
+func (p *Parser) renderDTD(parentDir string) error {
 
 	// we process here all the file path of all DTD parsed
 	// and determine the parent directory
@@ -255,65 +397,81 @@ func (p *Parser) renderDTD(parentDir string) {
 		p.Log.Debugf("ParentDir from filepaths is: %s", parentDir)
 	}
 
-	finalPath := p.determineFinalDTDPath(parentDir, p.Filepath)
+	finalPath, err := p.determineFinalDTDPath(parentDir, p.Filepath)
 
-	if _, err := os.Stat(finalPath); os.IsNotExist(err) {
-		p.Log.Infof("Create DTD: '%s'", finalPath)
-		p.createOutputFile(finalPath, false)
-	} else if p.Overwrite {
-		p.Log.Infof("Overwrite DTD: '%s'", finalPath)
-		p.createOutputFile(finalPath, true)
-	} else {
-		p.Log.Fatalf("Output DTD: '%s' already exists, please remove it before or use flag -overwrite", finalPath)
+	if err != nil {
+		return err
 	}
 
-	p.Log.Warnf("Render DTD '%s', %d blocks, %d nested parsers", finalPath, len(p.Collection), len(p.parsers))
+	p.Log.Infof("Create DTD: '%s', overwrite: %t", finalPath, p.Overwrite)
 
-	f := formatter.NewDTDFormatter(p.Log)
-	f.Render(&p.Collection, finalPath)
+	if err := p.createOutputFile(finalPath, p.Overwrite); err != nil {
+		return err
+	}
 
-	// process children parsers
-	//for idx, parser := range p.parsers {
-	//p.Log.Warnf("Render DTD's child '%d/%d'", idx+1, len(p.parsers))
-	//	parser.RenderDTD(parentDir)
-	//}
+	p.Log.Warnf("Render DTD '%s', %d blocks, %d nested parsers", finalPath, len(p.Collection), len(p.parsers))
 
+	f := formatter.NewDTDFormatter(p.Log)
+	return f.Render(&p.Collection, finalPath)
 }
 
 // RenderGoStructs Render a collection to a or a file containing go structs
-func (p *Parser) renderGoStructs(parentDir string, packageName string) {
+// the structs are rendered from the expanded DTD
+func (p *Parser) renderGoStructs(parentDir string, packageName string) error {
 
-	finalPath := p.determineFinalDTDPath(parentDir, "structs.go")
+	schema, err := p.Schema()
 
-	if _, err := os.Stat(finalPath); os.IsNotExist(err) {
-		p.Log.Infof("Create Go struct: '%s'", finalPath)
-		p.createOutputFile(finalPath, false)
-	} else if p.Overwrite {
-		p.Log.Infof("Overwrite: '%s'", finalPath)
-		p.createOutputFile(finalPath, true)
-	} else {
-		p.Log.Fatalf("Output Go struct: '%s' already exists, please remove it before or use flag -overwrite", finalPath)
+	if err != nil {
+		return err
+	}
+
+	finalPath, err := p.determineFinalDTDPath(parentDir, "structs.go")
+
+	if err != nil {
+		return err
+	}
+
+	p.Log.Infof("Create Go struct: '%s', overwrite: %t", finalPath, p.Overwrite)
+
+	if err := p.createOutputFile(finalPath, p.Overwrite); err != nil {
+		return err
 	}
 
 	p.Log.Warnf("Render DTD '%s', %d blocks, %d nested parsers", finalPath, len(p.Collection), len(p.parsers))
 
 	f := formatter.NewGoFormatter(p.Log, packageName)
-	f.Render(&p.Collection, finalPath)
-
-	// export every blocks
-	// for _, block := range p.Collection {
-	// 	p.Log.Debugf("Exporting block: %#v ", block)
-	// 	c := block.Render() + "\n"
-	// 	writeToFile(finalPath, c)
-	// }
-
-	// // process children parsers
-	// for _, parser := range p.parsers {
-	// 	parser.RenderDTD(parentDir)
-	// }
+	return f.Render(schema, finalPath)
+}
+
+// renderJSON Render the schema of the DTD to a JSON file named after the DTD
+func (p *Parser) renderJSON(parentDir string) error {
+
+	schema, err := p.Schema()
+
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(p.Filepath), filepath.Ext(p.Filepath)) + ".json"
+	finalPath, err := p.determineFinalDTDPath(parentDir, name)
+
+	if err != nil {
+		return err
+	}
+
+	p.Log.Infof("Create JSON: '%s', overwrite: %t", finalPath, p.Overwrite)
+
+	if err := p.createOutputFile(finalPath, p.Overwrite); err != nil {
+		return err
+	}
+
+	p.Log.Warnf("Render JSON '%s', %d blocks", finalPath, len(schema.Blocks))
+
+	f := formatter.NewJSONFormatter(p.Log)
+	return f.Render(schema, finalPath)
 }
 
-func (p *Parser) determineFinalDTDPath(parentDir string, i string) string {
+func (p *Parser) determineFinalDTDPath(parentDir string, i string) (string, error) {
 
 	p.Log.Debugf("determineFinalDTDPath: source is: '%s'", i)
 
@@ -333,13 +491,15 @@ func (p *Parser) determineFinalDTDPath(parentDir string, i string) string {
 
 	if _, err := os.Stat(oDir); os.IsNotExist(err) {
 		p.Log.Debugf("Create: %s", oDir)
-		os.MkdirAll(oDir, 0770)
+		if err := os.MkdirAll(oDir, 0770); err != nil {
+			return "", err
+		}
 	}
 
 	finalPath := oDir + "/" + filepath.Base(i)
 	p.Log.Debugf("finalPath %s", finalPath)
 
-	return finalPath
+	return finalPath, nil
 }
 
 // commonPrefix Given a slice of path (String) find the common shared directory path
@@ -0,0 +1,46 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTDParser A DTD parser
+package DTDParser
+
+import "github.com/blefort/DTDParser/DTD"
+
+// AttributeDefinitions returns the attributes of an element declared in the ATTLIST
+// declarations of the DTD and of its external modules, in document order
+// @ref https://www.w3.org/TR/xml11/#attdecls
+//
+// The DTD is expanded first if needed, so attributes declared with parameter entities
+// and in included conditional sections are found.
+// The first definition of an attribute is binding, the later ones are returned as warnings.
+func (p *Parser) AttributeDefinitions(element string) ([]DTD.Attribute, []*DuplicateAttributeWarning, error) {
+	var warnings []*DuplicateAttributeWarning
+
+	schema, err := p.Schema()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attributes, duplicates := schema.Attributes(element)
+
+	first := make(map[string]DTD.Pos)
+
+	for _, attr := range attributes {
+		first[attr.Name] = attr.Position
+	}
+
+	for _, attr := range duplicates {
+		w := &DuplicateAttributeWarning{
+			Element:   element,
+			Attribute: attr.Name,
+			Position:  attr.Position,
+			First:     first[attr.Name],
+		}
+		p.Log.Warnf("%v", w)
+		warnings = append(warnings, w)
+	}
+
+	return attributes, warnings, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTDParser A DTD parser
+package DTDParser
+
+import (
+	"fmt"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// ExternalReferenceError represents an external DTD that could not be found
+type ExternalReferenceError struct {
+	File   string
+	Line   int
+	Column int
+	Entity string
+	Url    string
+	Err    error
+}
+
+// Error implements error
+func (e *ExternalReferenceError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: external DTD '%s' declared in entity '%s' not found: %v", e.File, e.Line, e.Column, e.Url, e.Entity, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ExternalReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// OutputExistsError represents an output file that can't be overwritten
+type OutputExistsError struct {
+	Path string
+}
+
+// Error implements error
+func (e *OutputExistsError) Error() string {
+	return fmt.Sprintf("output '%s' already exists, please remove it before or use flag -overwrite", e.Path)
+}
+
+// UnknownFormatterError represents a formatter that is not available
+type UnknownFormatterError struct {
+	Formatter string
+}
+
+// Error implements error
+func (e *UnknownFormatterError) Error() string {
+	return fmt.Sprintf("formatter '%s' is not defined", e.Formatter)
+}
+
+// DuplicateAttributeWarning represents an attribute defined again for an element
+// the first definition, located at First, is used
+type DuplicateAttributeWarning struct {
+	Element   string
+	Attribute string
+	Position  DTD.Pos
+	First     DTD.Pos
+}
+
+// Error implements error
+func (w *DuplicateAttributeWarning) Error() string {
+	return fmt.Sprintf("%v: attribute '%s' of element '%s' already defined at %v, first definition is used", w.Position, w.Attribute, w.Element, w.First)
+}
@@ -0,0 +1,339 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTDParser A DTD parser
+package DTDParser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/blefort/DTDParser/DTD"
+	"github.com/blefort/DTDParser/scanner"
+)
+
+// peReference matches a parameter entity reference
+var peReference = regexp.MustCompile(`%([^\s%;"'<>]+);`)
+
+// expander resolves parameter entity references
+// @ref https://www.w3.org/TR/xml11/#sec-entexpand
+//
+// Entities are registered in document order, the first declaration of
+// an entity is binding, later ones are ignored.
+type expander struct {
+	log        *zap.SugaredLogger
+	parameters map[string]*DTD.Entity
+	general    map[string]*DTD.Entity
+	// original declaration and parser of each parameter entity
+	originals map[string]*DTD.Entity
+	owners    map[string]*Parser
+	// references being expanded, to detect recursion
+	active map[string]bool
+}
+
+// Expand resolve parameter entity references and store the result in Expanded
+//
+// - references in entity values, content models and attribute lists are replaced
+// - references between declarations are replaced by the declarations of the entity as declared before them
+// - included conditional sections are replaced by their declarations, ignored ones are removed
+// - redeclared entities are removed, the first declaration wins
+//
+// Collection is left untouched and can still be used to render the original DTD.
+// References to undeclared entities are kept and reported as warnings.
+func (p *Parser) Expand() error {
+	x := expander{
+		log:        p.Log,
+		parameters: make(map[string]*DTD.Entity),
+		general:    make(map[string]*DTD.Entity),
+		originals:  make(map[string]*DTD.Entity),
+		owners:     make(map[string]*Parser),
+		active:     make(map[string]bool),
+	}
+
+	expanded, err := x.expandBlocks(p, p.Collection)
+
+	if err != nil {
+		return err
+	}
+
+	p.Log.Infof("%d blocks after expansion of DTD '%s'", len(expanded), p.Filepath)
+	p.Expanded = expanded
+	return nil
+}
+
+// expandBlocks expand blocks declared in the DTD parsed by p
+func (x *expander) expandBlocks(p *Parser, blocks []DTD.IDTDBlock) ([]DTD.IDTDBlock, error) {
+	var expanded []DTD.IDTDBlock
+
+	for _, block := range blocks {
+		var result []DTD.IDTDBlock
+		var err error
+
+		switch b := block.(type) {
+		case *DTD.Entity:
+			result, err = x.expandEntity(p, b)
+		case *DTD.Element:
+			result, err = x.expandElement(p, b)
+		case *DTD.Attlist:
+			result, err = x.expandAttlist(p, b)
+		case *DTD.ConditionalSection:
+			result, err = x.expandConditionalSection(p, b)
+		case *DTD.Reference:
+			result, err = x.expandReference(p, b)
+		default:
+			result = []DTD.IDTDBlock{block}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		expanded = append(expanded, result...)
+	}
+
+	return expanded, nil
+}
+
+// expandEntity register an entity
+// the declarations of a parameter entity are expanded where it is referenced, see expandReference
+func (x *expander) expandEntity(p *Parser, e *DTD.Entity) ([]DTD.IDTDBlock, error) {
+	var expanded []DTD.IDTDBlock
+
+	entity := *e
+
+	if !entity.IsExternal {
+		entity.Value = x.replace(entity.Value, false)
+	}
+
+	table := x.general
+	if entity.Parameter {
+		table = x.parameters
+	}
+
+	if _, declared := table[entity.Name]; declared {
+		x.log.Debugf("Entity '%s' redeclared in '%s', first declaration is used", entity.Name, p.Filepath)
+	} else {
+		table[entity.Name] = &entity
+		expanded = append(expanded, &entity)
+
+		if entity.Parameter {
+			x.originals[entity.Name] = e
+			x.owners[entity.Name] = p
+		}
+	}
+
+	return expanded, nil
+}
+
+// expandReference returns the declarations of a parameter entity referenced between declarations
+// the entity is the one declared first before the reference, an undeclared reference is kept
+func (x *expander) expandReference(p *Parser, r *DTD.Reference) ([]DTD.IDTDBlock, error) {
+	name := r.Name
+
+	entity, declared := x.parameters[name]
+
+	if !declared {
+		x.log.Warnf("%s: parameter entity '%s' is not declared, reference is kept", r.Position, name)
+		return []DTD.IDTDBlock{r}, nil
+	}
+
+	if x.active[name] {
+		return nil, fmt.Errorf("%s: recursive reference to parameter entity '%s'", r.Position, name)
+	}
+
+	x.active[name] = true
+	defer delete(x.active, name)
+
+	owner := x.owners[name]
+
+	if entity.IsExternal {
+		ext := owner.externals[x.originals[name]]
+
+		if ext == nil {
+			x.log.Warnf("External entity '%s' was not parsed, its declarations are not expanded", name)
+			return nil, nil
+		}
+
+		return x.expandBlocks(ext, ext.Collection)
+	}
+
+	// the replacement text of an internal entity is scanned as declarations,
+	// they are located at the reference
+	sub := owner.newChildParser()
+
+	if err := sub.ParseReader(owner.Filepath, strings.NewReader(entity.Value)); err != nil {
+		return nil, err
+	}
+
+	DTD.Walk(&locator{pos: r.Position}, sub.Collection...)
+	return x.expandBlocks(sub, sub.Collection)
+}
+
+// expandElement expand the content model of an element
+func (x *expander) expandElement(p *Parser, e *DTD.Element) ([]DTD.IDTDBlock, error) {
+	element := *e
+	element.Value = x.replace(e.Value, true)
+
+	if element.Value == e.Value {
+		return []DTD.IDTDBlock{&element}, nil
+	}
+
+	content, err := scanner.ParseContentModel(element.Value)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: element '%s' after expansion: %v", p.Filepath, e.Name, err)
+	}
+
+	element.Content = content
+	return []DTD.IDTDBlock{&element}, nil
+}
+
+// expandAttlist replace attribute definitions declared with a parameter entity
+// definitions kept as written by the scanner are parsed once expanded
+func (x *expander) expandAttlist(p *Parser, a *DTD.Attlist) ([]DTD.IDTDBlock, error) {
+	if a.Value != "" {
+		attlist, err := scanner.ParseExpandedAttlist(a, x.lookup, x.log)
+
+		if err != nil {
+			return nil, fmt.Errorf("attributes '%s' of '%s' after expansion: %w", a.Value, a.Name, err)
+		}
+
+		// undeclared references are kept, the definitions can't be parsed yet
+		if attlist.Value != "" {
+			x.log.Warnf("Attributes '%s' of '%s' hold undeclared parameter entities, they are not parsed", a.Value, a.Name)
+		}
+		return []DTD.IDTDBlock{attlist}, nil
+	}
+
+	attlist := *a
+	attlist.Attributes = nil
+
+	for _, attr := range a.Attributes {
+
+		if !attr.IsEntity {
+			attlist.Attributes = append(attlist.Attributes, attr)
+			continue
+		}
+
+		// attributes are located at the reference
+		pos := attr.Position
+		reference := DTD.Attlist{Name: a.Name, Value: attr.Value, ValuePosition: &pos}
+		parsed, err := scanner.ParseExpandedAttlist(&reference, x.lookup, x.log)
+
+		if err != nil {
+			return nil, fmt.Errorf("attributes '%s' of '%s' after expansion: %w", attr.Value, a.Name, err)
+		}
+
+		if parsed.Value != "" {
+			x.log.Warnf("Attributes '%s' of '%s' hold undeclared parameter entities, reference is kept", attr.Value, a.Name)
+			attlist.Attributes = append(attlist.Attributes, attr)
+			continue
+		}
+
+		attlist.Attributes = append(attlist.Attributes, parsed.Attributes...)
+	}
+
+	return []DTD.IDTDBlock{&attlist}, nil
+}
+
+// expandConditionalSection returns the expanded declarations of an included section
+func (x *expander) expandConditionalSection(p *Parser, c *DTD.ConditionalSection) ([]DTD.IDTDBlock, error) {
+
+	included, err := c.Included(x.lookup)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", p.Filepath, err)
+	}
+
+	if !included {
+		x.log.Debugf("Conditional section '%s' ignored", c.Keyword)
+		return nil, nil
+	}
+
+	return x.expandBlocks(p, c.Blocks)
+}
+
+// lookup returns the replacement text of an internal parameter entity
+func (x *expander) lookup(name string) (string, bool) {
+	entity, ok := x.parameters[name]
+
+	if !ok || entity.IsExternal {
+		return "", false
+	}
+	return entity.Value, true
+}
+
+// replace replace parameter entity references in s
+// references outside literals are padded with spaces
+// @ref https://www.w3.org/TR/xml11/#as-PE
+func (x *expander) replace(s string, padded bool) string {
+	return peReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[1 : len(ref)-1]
+		value, ok := x.lookup(name)
+
+		if !ok {
+			x.log.Warnf("Parameter entity '%s' is not declared or is external, reference is kept", name)
+			return ref
+		}
+
+		if padded {
+			return " " + value + " "
+		}
+		return value
+	})
+}
+
+// locator sets the position of the blocks and attributes it visits
+type locator struct {
+	pos DTD.Pos
+}
+
+// VisitXMLDecl implements DTD.Visitor
+func (l *locator) VisitXMLDecl(x *DTD.XMLDecl) { x.Position = l.pos }
+
+// VisitProcessingInstruction implements DTD.Visitor
+func (l *locator) VisitProcessingInstruction(pi *DTD.ProcessingInstruction) { pi.Position = l.pos }
+
+// VisitComment implements DTD.Visitor
+func (l *locator) VisitComment(c *DTD.Comment) { c.Position = l.pos }
+
+// VisitElement implements DTD.Visitor
+func (l *locator) VisitElement(e *DTD.Element) bool {
+	e.Position = l.pos
+	return false
+}
+
+// VisitParticle implements DTD.Visitor
+func (l *locator) VisitParticle(p *DTD.Particle) bool { return false }
+
+// VisitAttlist implements DTD.Visitor
+func (l *locator) VisitAttlist(a *DTD.Attlist) bool {
+	a.Position = l.pos
+	if a.ValuePosition != nil {
+		pos := l.pos
+		a.ValuePosition = &pos
+	}
+	return true
+}
+
+// VisitAttribute implements DTD.Visitor
+func (l *locator) VisitAttribute(a *DTD.Attribute) { a.Position = l.pos }
+
+// VisitEntity implements DTD.Visitor
+func (l *locator) VisitEntity(e *DTD.Entity) { e.Position = l.pos }
+
+// VisitNotation implements DTD.Visitor
+func (l *locator) VisitNotation(n *DTD.Notation) { n.Position = l.pos }
+
+// VisitConditionalSection implements DTD.Visitor
+func (l *locator) VisitConditionalSection(c *DTD.ConditionalSection) bool {
+	c.Position = l.pos
+	return true
+}
+
+// VisitReference implements DTD.Visitor
+func (l *locator) VisitReference(r *DTD.Reference) { r.Position = l.pos }
@@ -0,0 +1,140 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTDParser A DTD parser
+package DTDParser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/blefort/DTDParser/catalog"
+)
+
+// EntityResolver resolves external entities
+// publicID and systemID identify the entity, baseURI locates the DTD declaring it.
+// It returns the content of the entity and its own base URI, used to resolve
+// the external entities it declares.
+// A resolver that does not know the entity returns an error wrapping fs.ErrNotExist.
+type EntityResolver interface {
+	Resolve(publicID string, systemID string, baseURI string) (io.ReadCloser, string, error)
+}
+
+// FileResolver resolves system IDs relatively to the base URI
+// FS is used when set, otherwise the local file system
+type FileResolver struct {
+	FS fs.FS
+}
+
+// Resolve implements EntityResolver
+func (r *FileResolver) Resolve(publicID string, systemID string, baseURI string) (io.ReadCloser, string, error) {
+
+	if systemID == "" {
+		return nil, "", fmt.Errorf("no system ID for '%s': %w", publicID, fs.ErrNotExist)
+	}
+
+	systemID = strings.TrimPrefix(systemID, "file://")
+
+	if r.FS != nil {
+		name := systemID
+		if !path.IsAbs(name) {
+			name = path.Join(path.Dir(baseURI), name)
+		}
+		f, err := r.FS.Open(name)
+		return f, name, err
+	}
+
+	name := systemID
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(baseURI), name)
+	}
+	f, err := os.Open(name)
+	return f, name, err
+}
+
+// MapResolver resolves entities from memory
+// keys are public IDs or system IDs, public IDs are looked up first
+type MapResolver map[string][]byte
+
+// Resolve implements EntityResolver
+func (r MapResolver) Resolve(publicID string, systemID string, baseURI string) (io.ReadCloser, string, error) {
+
+	if content, ok := r[publicID]; ok && publicID != "" {
+		return io.NopCloser(bytes.NewReader(content)), publicID, nil
+	}
+
+	if content, ok := r[systemID]; ok && systemID != "" {
+		return io.NopCloser(bytes.NewReader(content)), systemID, nil
+	}
+
+	return nil, "", fmt.Errorf("entity '%s' '%s' not in map: %w", publicID, systemID, fs.ErrNotExist)
+}
+
+// MultiResolver tries each resolver in turn until one knows the entity
+type MultiResolver []EntityResolver
+
+// Resolve implements EntityResolver
+func (r MultiResolver) Resolve(publicID string, systemID string, baseURI string) (io.ReadCloser, string, error) {
+	err := fmt.Errorf("no resolver for '%s' '%s': %w", publicID, systemID, fs.ErrNotExist)
+
+	for _, resolver := range r {
+		rc, base, rErr := resolver.Resolve(publicID, systemID, baseURI)
+
+		if rErr == nil {
+			return rc, base, nil
+		}
+
+		if !errors.Is(rErr, fs.ErrNotExist) {
+			return nil, "", rErr
+		}
+		err = rErr
+	}
+
+	return nil, "", err
+}
+
+// CatalogResolver resolves entities with OASIS XML Catalogs
+// Catalogs are consulted in order, the system ID is then looked up as a URI.
+// Entities unknown to the catalogs are resolved by Fallback.
+type CatalogResolver struct {
+	Catalogs []*catalog.Catalog
+	Fallback EntityResolver
+	FS       fs.FS
+}
+
+// Resolve implements EntityResolver
+func (r *CatalogResolver) Resolve(publicID string, systemID string, baseURI string) (io.ReadCloser, string, error) {
+	var uri string
+
+	for _, c := range r.Catalogs {
+		if uri = c.Resolve(publicID, systemID); uri != "" {
+			break
+		}
+	}
+
+	for _, c := range r.Catalogs {
+		if uri != "" || systemID == "" {
+			break
+		}
+		uri = c.ResolveURI(systemID)
+	}
+
+	if uri == "" && r.Fallback != nil {
+		return r.Fallback.Resolve(publicID, systemID, baseURI)
+	}
+
+	if uri == "" {
+		return nil, "", fmt.Errorf("entity '%s' '%s' not in catalogs: %w", publicID, systemID, fs.ErrNotExist)
+	}
+
+	files := FileResolver{FS: r.FS}
+	return files.Resolve(publicID, uri, "")
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package DTDParser A DTD parser
+package DTDParser
+
+import "github.com/blefort/DTDParser/DTD"
+
+// ParseSchema Parse a DTD using its path and return its schema
+func (p *Parser) ParseSchema(filePath string) (*DTD.Schema, error) {
+	if err := p.Parse(filePath); err != nil {
+		return nil, err
+	}
+	return p.Schema()
+}
+
+// Schema returns the model of the parsed DTD and of its external DTDs
+// The DTD is expanded first if needed.
+func (p *Parser) Schema() (*DTD.Schema, error) {
+	if err := p.expandOnce(); err != nil {
+		return nil, err
+	}
+	return DTD.NewSchema(p.module(), p.Expanded), nil
+}
+
+// expandOnce expand the DTD if it was not expanded yet
+func (p *Parser) expandOnce() error {
+	if p.Expanded != nil {
+		return nil
+	}
+	return p.Expand()
+}
+
+// module returns the include tree of the DTD parsed by p
+func (p *Parser) module() *DTD.Module {
+	m := DTD.Module{
+		File:     p.Filepath,
+		Encoding: p.Encoding,
+		Blocks:   p.Collection,
+	}
+
+	for _, e := range externalEntities(p.Collection) {
+		if ext := p.externals[e]; ext != nil {
+			m.Includes = append(m.Includes, &DTD.Include{Entity: e, Module: ext.module()})
+		}
+	}
+
+	return &m
+}
+
+// externalEntities returns the external entities declared in blocks and in their conditional sections
+func externalEntities(blocks []DTD.IDTDBlock) []*DTD.Entity {
+	var v externalEntityVisitor
+	DTD.Walk(&v, blocks...)
+	return v.entities
+}
+
+// externalEntityVisitor collects external entities
+type externalEntityVisitor struct {
+	DTD.BaseVisitor
+	entities []*DTD.Entity
+}
+
+// VisitEntity implements DTD.Visitor
+func (v *externalEntityVisitor) VisitEntity(e *DTD.Entity) {
+	if e.IsExternal {
+		v.entities = append(v.entities, e)
+	}
+}
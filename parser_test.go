@@ -3,7 +3,6 @@ package main
 import (
 	"encoding/json"
 	"flag"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -17,6 +16,7 @@ import (
 const dirTest = "tests/"
 
 var overwrite bool
+var update bool
 var log *zap.SugaredLogger
 
 // TestMain Test Initialization
@@ -26,6 +26,7 @@ func TestMain(m *testing.M) {
 
 	verbosity := flag.String("verbose", "v", "Verbose v, vv or vvv")
 	overwriteF := flag.Bool("overwrite", false, "Overwrite output file")
+	updateF := flag.Bool("update", false, "Update golden files")
 
 	if *verbosity == "v" {
 		level = zap.NewAtomicLevelAt(zap.WarnLevel)
@@ -80,6 +81,8 @@ func TestMain(m *testing.M) {
 		overwrite = true
 	}
 
+	update = *updateF
+
 	os.Exit(m.Run())
 }
 
@@ -101,16 +104,18 @@ func loadJSON(file string, v interface{}) {
 //  newParser() Instantiate parser and configure it
 func newParser(dir string) *DTDParser.Parser {
 
-	fmt.Sprintf("new parser %p", log)
 	// New parser
 	p := DTDParser.NewDTDParser(log)
 
 	// Configure parser
 	p.WithComments = true
 	p.IgnoreExtRefIssue = true
-	p.SetFormatter("DTD")
 	p.SetOutputPath(dir)
 
+	if err := p.SetFormatter("DTD"); err != nil {
+		panic(err)
+	}
+
 	if overwrite {
 		p.Overwrite = overwrite
 	}
@@ -120,8 +125,9 @@ func newParser(dir string) *DTDParser.Parser {
 // Render
 func render(p *DTDParser.Parser) func(*testing.T) {
 	return func(t *testing.T) {
-		fmt.Printf("pointer: %p", log)
-		p.Render("")
+		if err := p.Render(""); err != nil {
+			t.Errorf("Rendering failed: %v", err)
+		}
 	}
 }
 
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+	"github.com/blefort/DTDParser/scanner"
+)
+
+// TestParsePIBlock Test parser for text declarations and processing instructions
+func TestParsePIBlock(t *testing.T) {
+	// - parse the DTD test
+	// - render it in a temporary dir
+	tmp := t.TempDir()
+	testPIDTD(t, "tests/pi.dtd", tmp)
+
+	// - load the generated DTD
+	testPIDTD(t, filepath.Join(tmp, "pi.dtd"), t.TempDir())
+}
+
+// testPIDTD Main func holding tests
+func testPIDTD(t *testing.T, path string, dir string) {
+
+	// New parser
+	p := newParser(dir)
+
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
+
+	if len(p.Collection) != 4 {
+		t.Fatalf("Number of blocks in the collection (%d) differs from 4", len(p.Collection))
+	}
+
+	decl, ok := p.Collection[0].(*DTD.XMLDecl)
+
+	if !ok {
+		t.Fatalf("Expected a text declaration, got %#v", p.Collection[0])
+	}
+
+	t.Run("Check version", checkStrValue(decl.Version, "1.0", decl, nil))
+	t.Run("Check encoding", checkStrValue(decl.Encoding, "UTF-8", decl, nil))
+	t.Run("Check render", checkStrValue(decl.Render(), "<?xml version=\"1.0\" encoding=\"UTF-8\"?>", decl, nil))
+
+	pi := p.Collection[1].(*DTD.ProcessingInstruction)
+	t.Run("Check target", checkStrValue(pi.GetName(), "xml-stylesheet", pi, nil))
+	t.Run("Check value", checkStrValue(pi.GetValue(), "type=\"text/xsl\" href=\"style.xsl\"", pi, nil))
+
+	empty := p.Collection[3].(*DTD.ProcessingInstruction)
+	t.Run("Check empty target", checkStrValue(empty.Target, "dtd-generator", empty, nil))
+	t.Run("Check empty render", checkStrValue(empty.Render(), "<?dtd-generator?>", empty, nil))
+
+	t.Run("Render DTD", render(p))
+}
+
+// TestMisplacedXMLDecl Test a text declaration not at the start of the DTD
+func TestMisplacedXMLDecl(t *testing.T) {
+	var syntaxErr *scanner.SyntaxError
+
+	p := newParser(t.TempDir())
+	err := p.ParseReader("inline.dtd", strings.NewReader("<!ELEMENT a EMPTY>\n<?xml version=\"1.0\"?>"))
+
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected a syntax error, got %v", err)
+	}
+
+	t.Run("Check line", checkIntValue(syntaxErr.Line, 2, syntaxErr, nil))
+}
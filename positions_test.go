@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// checkPos returns a test function comparing a position to the expected one
+func checkPos(pos DTD.Pos, expected DTD.Pos) func(*testing.T) {
+	return func(t *testing.T) {
+		if pos != expected {
+			t.Errorf("Position %#v differs from %#v", pos, expected)
+		}
+	}
+}
+
+// TestPositions Test the position of blocks and attributes
+func TestPositions(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	src := "<!-- é -->\n" +
+		"<!ELEMENT a (b)*>\n" +
+		"  <!ATTLIST a\n" +
+		"    id ID #REQUIRED\n" +
+		"    class CDATA \"x\">\n" +
+		"<![INCLUDE[\n" +
+		"  <!ELEMENT b EMPTY>\n" +
+		"]]>"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if len(p.Collection) != 4 {
+		t.Fatalf("Number of blocks (%d) differs from 4", len(p.Collection))
+	}
+
+	t.Run("Check comment", checkPos(p.Collection[0].Pos(), DTD.Pos{File: "inline.dtd", Offset: 0, Line: 1, Column: 1, EndOffset: 11, EndLine: 1, EndColumn: 11}))
+	t.Run("Check element", checkPos(p.Collection[1].Pos(), DTD.Pos{File: "inline.dtd", Offset: 12, Line: 2, Column: 1, EndOffset: 29, EndLine: 2, EndColumn: 18}))
+	t.Run("Check attlist", checkPos(p.Collection[2].Pos(), DTD.Pos{File: "inline.dtd", Offset: 32, Line: 3, Column: 3, EndOffset: 84, EndLine: 5, EndColumn: 21}))
+
+	attlist := p.Collection[2].(*DTD.Attlist)
+	t.Run("Check first attribute", checkPos(attlist.Attributes[0].Position, DTD.Pos{File: "inline.dtd", Offset: 48, Line: 4, Column: 5, EndOffset: 63, EndLine: 4, EndColumn: 20}))
+	t.Run("Check second attribute", checkPos(attlist.Attributes[1].Position, DTD.Pos{File: "inline.dtd", Offset: 68, Line: 5, Column: 5, EndOffset: 83, EndLine: 5, EndColumn: 20}))
+
+	section := p.Collection[3].(*DTD.ConditionalSection)
+	t.Run("Check section", checkPos(section.Pos(), DTD.Pos{File: "inline.dtd", Offset: 85, Line: 6, Column: 1, EndOffset: 121, EndLine: 8, EndColumn: 4}))
+	t.Run("Check section block", checkPos(section.Blocks[0].Pos(), DTD.Pos{File: "inline.dtd", Offset: 99, Line: 7, Column: 3, EndOffset: 117, EndLine: 7, EndColumn: 21}))
+}
+
+// TestExternalPositions Test the position of blocks declared in an external entity
+func TestExternalPositions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.dtd": {Data: []byte("<!ENTITY % ext SYSTEM \"ext.ent\">\n%ext;\n")},
+		"ext.ent":  {Data: []byte("\n<!ELEMENT b EMPTY>")},
+	}
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	if err := p.Parse("main.dtd"); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.Expand(); err != nil {
+		t.Fatalf("Expansion failed: %v", err)
+	}
+
+	if len(p.Expanded) != 2 {
+		t.Fatalf("Number of expanded blocks (%d) differs from 2", len(p.Expanded))
+	}
+
+	t.Run("Check entity", checkPos(p.Expanded[0].Pos(), DTD.Pos{File: "main.dtd", Offset: 0, Line: 1, Column: 1, EndOffset: 32, EndLine: 1, EndColumn: 33}))
+	t.Run("Check external block", checkPos(p.Expanded[1].Pos(), DTD.Pos{File: "ext.ent", Offset: 1, Line: 2, Column: 1, EndOffset: 19, EndLine: 2, EndColumn: 19}))
+}
+
+// TestExpandedPositions Test the position of expanded blocks and attributes
+func TestExpandedPositions(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	src := "<!ENTITY % yesno \"(yes|no)\">\n" +
+		"<!ENTITY % atts \"id ID #IMPLIED\">\n" +
+		"<!ENTITY % decls \"<!ELEMENT x EMPTY>\">\n" +
+		"<!ATTLIST a\n" +
+		"  b %yesno; #IMPLIED\n" +
+		"  c CDATA 'x'>\n" +
+		"<!ATTLIST x %atts; lang CDATA #IMPLIED>\n" +
+		"  %decls;\n"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.Expand(); err != nil {
+		t.Fatalf("Expansion failed: %v", err)
+	}
+
+	if len(p.Expanded) != 6 {
+		t.Fatalf("Number of expanded blocks (%d) differs from 6: %#v", len(p.Expanded), p.Expanded)
+	}
+
+	// attributes parsed again keep their own position
+	a := p.Expanded[3].(*DTD.Attlist)
+	t.Run("Check attribute with a reference", checkPos(a.Attributes[0].Position, DTD.Pos{File: "inline.dtd", Offset: 116, Line: 5, Column: 3, EndOffset: 134, EndLine: 5, EndColumn: 21}))
+	t.Run("Check attribute after a reference", checkPos(a.Attributes[1].Position, DTD.Pos{File: "inline.dtd", Offset: 137, Line: 6, Column: 3, EndOffset: 148, EndLine: 6, EndColumn: 14}))
+
+	// attributes of an entity are located at the reference
+	x := p.Expanded[4].(*DTD.Attlist)
+	t.Run("Check attribute of an entity", checkPos(x.Attributes[0].Position, DTD.Pos{File: "inline.dtd", Offset: 162, Line: 7, Column: 13, EndOffset: 168, EndLine: 7, EndColumn: 19}))
+	t.Run("Check attribute following an entity", checkIntValue(x.Attributes[1].Position.Column, 20, x.Attributes[1], nil))
+
+	// declarations of an entity are located at the reference
+	t.Run("Check block of an entity", checkPos(p.Expanded[5].Pos(), DTD.Pos{File: "inline.dtd", Offset: 192, Line: 8, Column: 3, EndOffset: 199, EndLine: 8, EndColumn: 10}))
+}
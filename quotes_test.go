@@ -0,0 +1,124 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+	DTDParser "github.com/blefort/DTDParser/parser"
+)
+
+// TestParseQuotes Test literals delimited by single and double quotes
+func TestParseQuotes(t *testing.T) {
+	// - parse the DTD test
+	// - render it in a temporary dir
+	tmp := t.TempDir()
+	testQuotesDTD(t, "tests/quotes.dtd", tmp)
+
+	// - load the generated DTD
+	testQuotesDTD(t, filepath.Join(tmp, "quotes.dtd"), t.TempDir())
+}
+
+// testQuotesDTD Main func holding tests
+func testQuotesDTD(t *testing.T, path string, dir string) {
+
+	// New parser
+	p := newParser(dir)
+
+	if err := p.Parse(path); err != nil {
+		t.Fatalf("Parsing '%s' failed: %v", path, err)
+	}
+
+	if len(p.Collection) != 6 {
+		t.Fatalf("Number of blocks in the collection (%d) differs from 6", len(p.Collection))
+	}
+
+	title := p.Collection[0].(*DTD.Entity)
+	t.Run("Check value with double quotes", checkStrValue(title.Value, "The \"Plague\"", title, nil))
+	t.Run("Check single quote", checkStrValue(title.Quote, DTD.SingleQuote, title, nil))
+	t.Run("Check render", checkStrValue(title.Render(), "<!ENTITY title 'The \"Plague\"'\n>\n", title, nil))
+
+	author := p.Collection[1].(*DTD.Entity)
+	t.Run("Check double quote", checkStrValue(author.Quote, DTD.DoubleQuote, author, nil))
+
+	logo := p.Collection[2].(*DTD.Entity)
+	t.Run("Check public id", checkStrValue(logo.Value, "-//ACME//TEXT Logo//EN", logo, nil))
+	t.Run("Check public id quote", checkStrValue(logo.Quote, DTD.SingleQuote, logo, nil))
+	t.Run("Check system literal", checkStrValue(logo.Url, "logo's.xml", logo, nil))
+	t.Run("Check system literal quote", checkStrValue(logo.UrlQuote, DTD.DoubleQuote, logo, nil))
+
+	chapter := p.Collection[3].(*DTD.Entity)
+	t.Run("Check system literal with double quotes", checkStrValue(chapter.Url, "chapter\"1\".xml", chapter, nil))
+
+	png := p.Collection[4].(*DTD.Notation)
+	t.Run("Check notation public id quote", checkStrValue(png.PublicIDQuote, DTD.SingleQuote, png, nil))
+	t.Run("Check notation system id quote", checkStrValue(png.SystemIDQuote, DTD.SingleQuote, png, nil))
+	t.Run("Check notation render", checkStrValue(png.Render(), "<!NOTATION png PUBLIC '-//ACME//NOTATION PNG//EN' 'image/png'>", png, nil))
+
+	attlist := p.Collection[5].(*DTD.Attlist)
+	t.Run("Check attribute value", checkStrValue(attlist.Attributes[0].Value, "Camus, \"A.\"", attlist, nil))
+	t.Run("Check attribute quote", checkStrValue(attlist.Attributes[0].Quote, DTD.SingleQuote, attlist, nil))
+	t.Run("Check fixed value", checkStrValue(attlist.Attributes[1].Value, "l'fr", attlist, nil))
+	t.Run("Check fixed quote", checkStrValue(attlist.Attributes[1].Quote, DTD.DoubleQuote, attlist, nil))
+
+	t.Run("Render DTD", render(p))
+}
+
+// TestQuoteDelimiter Test the delimiter of a literal is changed when it contains it
+func TestQuoteDelimiter(t *testing.T) {
+	t.Run("Check default", checkStrValue(DTD.Quote("a", ""), "\"a\"", nil, nil))
+	t.Run("Check single", checkStrValue(DTD.Quote("a", DTD.SingleQuote), "'a'", nil, nil))
+	t.Run("Check single in value", checkStrValue(DTD.Quote("it's", DTD.SingleQuote), "\"it's\"", nil, nil))
+	t.Run("Check double in value", checkStrValue(DTD.Quote("a \"b\"", DTD.DoubleQuote), "'a \"b\"'", nil, nil))
+}
+
+// TestWhitespaceLiterals Test white spaces of literals are kept when they are rendered
+func TestWhitespaceLiterals(t *testing.T) {
+	src := "<!ENTITY padded \"  padded  \">\n" +
+		"<!ENTITY % sp \" \">\n" +
+		"<!ENTITY logo PUBLIC ' -//ACME//TEXT Logo//EN ' \" logo.xml \">\n" +
+		"<!NOTATION png SYSTEM \" image/png \">\n" +
+		"<!ATTLIST a b CDATA '  x  ' c CDATA #FIXED \" y \">\n"
+
+	dir := t.TempDir()
+	p := newParser(dir)
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := p.Render(""); err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+
+	// the rendered DTD is parsed again
+	rendered := newParser(t.TempDir())
+
+	if err := rendered.Parse(filepath.Join(dir, "inline.dtd")); err != nil {
+		t.Fatalf("Parsing the rendered DTD failed: %v", err)
+	}
+
+	for _, parsed := range []*DTDParser.Parser{p, rendered} {
+		if len(parsed.Collection) != 5 {
+			t.Fatalf("Number of blocks in the collection (%d) differs from 5", len(parsed.Collection))
+		}
+
+		padded := parsed.Collection[0].(*DTD.Entity)
+		t.Run("Check entity value", checkStrValue(padded.Value, "  padded  ", padded, nil))
+
+		sp := parsed.Collection[1].(*DTD.Entity)
+		t.Run("Check space", checkStrValue(sp.Value, " ", sp, nil))
+
+		logo := parsed.Collection[2].(*DTD.Entity)
+		t.Run("Check public id", checkStrValue(logo.Value, " -//ACME//TEXT Logo//EN ", logo, nil))
+		t.Run("Check system literal", checkStrValue(logo.Url, " logo.xml ", logo, nil))
+
+		png := parsed.Collection[3].(*DTD.Notation)
+		t.Run("Check notation system id", checkStrValue(png.SystemID, " image/png ", png, nil))
+
+		attlist := parsed.Collection[4].(*DTD.Attlist)
+		t.Run("Check default value", checkStrValue(attlist.Attributes[0].DefaultValue, "  x  ", attlist, nil))
+		t.Run("Check fixed value", checkStrValue(attlist.Attributes[1].DefaultValue, " y ", attlist, nil))
+	}
+}
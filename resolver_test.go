@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	DTDParser "github.com/blefort/DTDParser/parser"
+)
+
+const ditaHighlight = "-//OASIS//ENTITIES DITA 1.2 Highlight Domain//EN"
+
+// TestMapResolver Test redirecting a public ID to an in-memory DTD
+func TestMapResolver(t *testing.T) {
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetResolver(DTDParser.MultiResolver{
+		DTDParser.MapResolver{ditaHighlight: []byte("<!ELEMENT b (#PCDATA)>")},
+		&DTDParser.FileResolver{},
+	})
+
+	r := strings.NewReader("<!ENTITY % hi-d-dec PUBLIC \"" + ditaHighlight + "\" \"vendor/highlight.ent\">%hi-d-dec;")
+
+	if err := p.ParseReader("tests/inline.dtd", r); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	schema, err := p.Schema()
+
+	if err != nil {
+		t.Fatalf("Expanding failed: %v", err)
+	}
+
+	b, ok := schema.Elements["b"]
+
+	t.Run("Check element", checkBoolValue(ok, true, schema.Elements, nil))
+	if ok {
+		t.Run("Check element value", checkStrValue(b.Value, " (#PCDATA)", b, nil))
+	}
+}
+
+// TestFileResolver Test system IDs are resolved relatively to the base URI
+func TestFileResolver(t *testing.T) {
+	var r DTDParser.FileResolver
+
+	rc, base, err := r.Resolve("", "external.ent", "tests/entity.dtd")
+
+	if err != nil {
+		t.Fatalf("Resolving failed: %v", err)
+	}
+	defer rc.Close()
+
+	content, _ := io.ReadAll(rc)
+
+	t.Run("Check base", checkStrValue(base, "tests/external.ent", base, nil))
+	t.Run("Check content", checkStrValue(string(content), "<!--Comment test-->", base, nil))
+
+	if _, _, err := r.Resolve("", "missing.ent", "tests/entity.dtd"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a not exist error, got '%v'", err)
+	}
+}
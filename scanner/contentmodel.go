@@ -0,0 +1,284 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// contentModelParser is a recursive descent parser for element content specifications
+type contentModelParser struct {
+	input []rune
+	pos   int
+}
+
+// ParseContentModel parse the content specification of an element
+// @ref https://www.w3.org/TR/xml11/#elemdecls
+//
+// [46]   	contentspec	   ::=   	'EMPTY' | 'ANY' | Mixed | children
+// [47]   	children	   ::=   	(choice | seq) ('?' | '*' | '+')?
+// [48]   	cp	           ::=   	(Name | choice | seq) ('?' | '*' | '+')?
+// [49]   	choice	       ::=   	'(' S? cp ( S? '|' S? cp )+ S? ')'
+// [50]   	seq	           ::=   	'(' S? cp ( S? ',' S? cp )* S? ')'
+// [51]   	Mixed	       ::=   	'(' S? '#PCDATA' (S? '|' S? Name)* S? ')*' | '(' S? '#PCDATA' S? ')'
+//
+// Parameter entity references are kept as PARTICLE_ENTITY particles
+func ParseContentModel(s string) (*DTD.ContentModel, error) {
+	var c DTD.ContentModel
+
+	s = strings.TrimSpace(s)
+
+	switch s {
+	case "EMPTY":
+		c.Type = DTD.CONTENT_EMPTY
+		return &c, nil
+	case "ANY":
+		c.Type = DTD.CONTENT_ANY
+		return &c, nil
+	case "":
+		return nil, fmt.Errorf("empty content specification")
+	}
+
+	p := contentModelParser{input: []rune(s)}
+
+	root, err := p.parseCp()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, p.errorf("unexpected '%c' after content specification", p.peek())
+	}
+
+	c.Root = root
+
+	if err := checkPCDATA(root, root); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root.Type == DTD.PARTICLE_ENTITY:
+		c.Type = DTD.CONTENT_ENTITY
+	case isMixed(root):
+		c.Type = DTD.CONTENT_MIXED
+		if err := checkMixed(root); err != nil {
+			return nil, err
+		}
+	case root.IsGroup():
+		c.Type = DTD.CONTENT_CHILDREN
+	default:
+		return nil, fmt.Errorf("content specification '%s' must be a group", s)
+	}
+
+	return &c, nil
+}
+
+// isMixed tells if the root particle is a Mixed declaration
+func isMixed(root *DTD.Particle) bool {
+	return root.IsGroup() && len(root.Children) > 0 && root.Children[0].Type == DTD.PARTICLE_PCDATA
+}
+
+// checkMixed check constraints of production [51]
+func checkMixed(root *DTD.Particle) error {
+	if root.Children[0].Occurrence != DTD.ONCE {
+		return fmt.Errorf("#PCDATA can't have an occurrence indicator")
+	}
+	if len(root.Children) == 1 && root.Occurrence != DTD.ONCE && root.Occurrence != DTD.ZERO_OR_MORE {
+		return fmt.Errorf("mixed content '(#PCDATA)' only accepts '*'")
+	}
+	if len(root.Children) > 1 && root.Type != DTD.PARTICLE_CHOICE {
+		return fmt.Errorf("mixed content must be a choice")
+	}
+	if len(root.Children) > 1 && root.Occurrence != DTD.ZERO_OR_MORE {
+		return fmt.Errorf("mixed content with element names must end with ')*'")
+	}
+	for _, child := range root.Children[1:] {
+		if child.Type != DTD.PARTICLE_NAME && child.Type != DTD.PARTICLE_ENTITY || child.Occurrence != DTD.ONCE {
+			return fmt.Errorf("mixed content only accepts names, found '%s'", child.Render())
+		}
+	}
+	return nil
+}
+
+// checkPCDATA check that #PCDATA is only found first in the root group, production [51]
+func checkPCDATA(p *DTD.Particle, root *DTD.Particle) error {
+	for i, child := range p.Children {
+		if child.Type == DTD.PARTICLE_PCDATA && (p != root || i > 0) {
+			return fmt.Errorf("#PCDATA must come first in a mixed content")
+		}
+		if err := checkPCDATA(child, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCp parse a content particle
+func (p *contentModelParser) parseCp() (*DTD.Particle, error) {
+	var particle *DTD.Particle
+	var err error
+
+	p.skipSpaces()
+
+	if p.eof() {
+		return nil, p.errorf("unexpected end of content specification")
+	}
+
+	switch r := p.peek(); {
+	case r == '(':
+		particle, err = p.parseGroup()
+	case r == '%':
+		particle, err = p.parseEntityRef()
+	case r == '#':
+		particle, err = p.parsePCDATA()
+	case isNameRune(r):
+		particle = &DTD.Particle{Type: DTD.PARTICLE_NAME, Name: p.readName()}
+	default:
+		return nil, p.errorf("unexpected '%c'", r)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	particle.Occurrence = p.readOccurrence()
+	return particle, nil
+}
+
+// parseGroup parse a choice or a sequence
+func (p *contentModelParser) parseGroup() (*DTD.Particle, error) {
+	var group DTD.Particle
+
+	// consume '('
+	p.pos++
+	group.Type = DTD.PARTICLE_SEQUENCE
+	separator := rune(0)
+
+	for {
+		cp, err := p.parseCp()
+		if err != nil {
+			return nil, err
+		}
+		group.Children = append(group.Children, cp)
+
+		p.skipSpaces()
+		if p.eof() {
+			return nil, p.errorf("missing ')'")
+		}
+
+		r := p.peek()
+		p.pos++
+
+		if r == ')' {
+			return &group, nil
+		}
+
+		if r != '|' && r != ',' {
+			return nil, p.errorf("unexpected '%c' in group", r)
+		}
+
+		if separator != 0 && separator != r {
+			return nil, p.errorf("'|' and ',' can't be mixed in the same group")
+		}
+
+		separator = r
+		if r == '|' {
+			group.Type = DTD.PARTICLE_CHOICE
+		}
+	}
+}
+
+// parseEntityRef parse a parameter entity reference
+func (p *contentModelParser) parseEntityRef() (*DTD.Particle, error) {
+	// consume '%'
+	p.pos++
+	name := p.readName()
+
+	if name == "" || p.eof() || p.peek() != ';' {
+		return nil, p.errorf("malformed parameter entity reference")
+	}
+	p.pos++
+
+	return &DTD.Particle{Type: DTD.PARTICLE_ENTITY, Name: name}, nil
+}
+
+// parsePCDATA parse the #PCDATA keyword
+func (p *contentModelParser) parsePCDATA() (*DTD.Particle, error) {
+	// consume '#'
+	p.pos++
+	if p.readName() != "PCDATA" {
+		return nil, p.errorf("expected #PCDATA")
+	}
+	return &DTD.Particle{Type: DTD.PARTICLE_PCDATA}, nil
+}
+
+// readOccurrence read an optional occurrence indicator
+func (p *contentModelParser) readOccurrence() int {
+	if p.eof() {
+		return DTD.ONCE
+	}
+
+	o := DTD.ONCE
+
+	switch p.peek() {
+	case '?':
+		o = DTD.OPTIONAL
+	case '*':
+		o = DTD.ZERO_OR_MORE
+	case '+':
+		o = DTD.ONE_OR_MORE
+	default:
+		return o
+	}
+	p.pos++
+	return o
+}
+
+// readName read a Name
+func (p *contentModelParser) readName() string {
+	start := p.pos
+	for !p.eof() && isNameRune(p.peek()) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// skipSpaces skip white spaces
+func (p *contentModelParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *contentModelParser) peek() rune {
+	return p.input[p.pos]
+}
+
+func (p *contentModelParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *contentModelParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("content specification '%s', offset %d: %s", string(p.input), p.pos, fmt.Sprintf(format, args...))
+}
+
+// isNameRune tells if r can be part of a Name
+// @ref https://www.w3.org/TR/xml11/#NT-NameChar
+func isNameRune(r rune) bool {
+	if unicode.IsSpace(r) {
+		return false
+	}
+	switch r {
+	case '(', ')', '|', ',', '?', '*', '+', '%', ';', '#', '"', '\'', '<', '>':
+		return false
+	}
+	return true
+}
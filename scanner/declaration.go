@@ -0,0 +1,556 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package scanner
+
+import (
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// errUnexpandedReference is returned when a parameter entity reference is found
+// where its replacement text is needed to go on
+var errUnexpandedReference = errors.New("parameter entity reference must be expanded")
+
+// declaration holds the tokens of a markup declaration
+// tokens are the tokens between the keyword and '>', words the ones that are not white spaces
+type declaration struct {
+	open   Token
+	close  Token
+	tokens []Token
+	words  []Token
+}
+
+// readDeclaration reads the tokens of the declaration opened by open
+func (sc *DTDScanner) readDeclaration(open Token) (*declaration, error) {
+	// declarations do not nest, the buffers of the previous one are reused
+	d := declaration{open: open, tokens: sc.tokens[:0], words: sc.words[:0]}
+	defer func() { sc.tokens, sc.words = d.tokens, d.words }()
+
+	for {
+		t, err := sc.next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t.Type {
+		case TokenDeclClose:
+			d.close = t
+			return &d, nil
+		case TokenEOF, TokenDeclOpen, TokenSectionOpen, TokenSectionClose, TokenComment, TokenPI:
+			// the next block starts with t
+			sc.unread(t)
+			return nil, sc.syntaxError("unterminated declaration '%s'", d.read()).expecting("'>'")
+		case TokenWhitespace:
+		default:
+			d.words = append(d.words, t)
+		}
+
+		d.tokens = append(d.tokens, t)
+	}
+}
+
+// read returns the source text of the declaration
+func (d *declaration) read() string {
+	var sb strings.Builder
+
+	sb.WriteString(d.open.Text)
+	for _, t := range d.tokens {
+		sb.WriteString(t.Text)
+	}
+	sb.WriteString(d.close.Text)
+
+	return sb.String()
+}
+
+// position returns the position of the declaration
+func (d *declaration) position() DTD.Pos {
+	return span(d.open.Pos, d.close.Pos)
+}
+
+// textAfter returns the source text following the word w, white spaces are normalized
+func (d *declaration) textAfter(w Token) string {
+	return strings.Join(strings.Fields(d.sourceAfter(w)), " ")
+}
+
+// sourceAfter returns the source text following the word w
+func (d *declaration) sourceAfter(w Token) string {
+	var sb strings.Builder
+
+	for _, t := range d.tokens {
+		if t.Pos.Offset > w.Pos.Offset {
+			sb.WriteString(t.Text)
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// isName tells if the word w is a name or a parameter entity reference used as a name
+func isName(w Token) bool {
+	return w.Type == TokenName || w.Type == TokenPEReference
+}
+
+// quote returns the delimiter of the literal w
+// @ref https://www.w3.org/TR/xml11/#NT-SystemLiteral
+func quote(w Token) string {
+	return w.Text[:1]
+}
+
+// isKeyword tells if the word w is the keyword k
+func isKeyword(w Token, k string) bool {
+	return w.Type == TokenName && w.Value == k
+}
+
+// ParseElement Use the information in the declaration to return a pointer to a DTD.Element
+// @ref https://www.w3.org/TR/xml11/#elemdecls
+//
+// Element Declaration
+// [45]   	elementdecl	   ::=   	'<!ELEMENT' S Name S contentspec S? '>'	[VC: Unique Element Type Declaration]
+// [46]   	contentspec	   ::=   	'EMPTY' | 'ANY' | Mixed | children
+//
+// The content specification is parsed by ParseContentModel
+func (sc *DTDScanner) ParseElement(d *declaration) (*DTD.Element, error) {
+	var e DTD.Element
+
+	e.Position = d.position()
+	words := d.words
+
+	if len(words) < 2 || !isName(words[0]) {
+		return nil, sc.syntaxError("not enough arguments in element declaration '%s'", d.read()).expecting("a name and a content specification")
+	}
+	e.Name = words[0].Text
+	e.Value = " " + d.textAfter(words[0])
+
+	content, err := ParseContentModel(e.Value)
+
+	if err != nil {
+		return nil, sc.syntaxError("element '%s': %v", e.Name, err).expecting("EMPTY, ANY, mixed content or a content model")
+	}
+	e.Content = content
+
+	sc.Log.Info("ParseElement ", e.Name)
+	return &e, nil
+}
+
+// ParseNotation Use the information in the declaration to return a pointer to a DTD.Notation
+// @ref https://www.w3.org/TR/xml11/#Notations
+//
+// # Element Declaration
+//
+// [82]  NotationDec ::= '<!NOTATION' S Name S (ExternalID | PublicID) S? '>'  [VC: Unique Notation Name]
+// [83]  PublicID    ::= 'PUBLIC' S PubidLiteral
+func (sc *DTDScanner) ParseNotation(d *declaration) (*DTD.Notation, error) {
+	var n DTD.Notation
+
+	n.Position = d.position()
+	words := d.words
+	l := len(words)
+
+	if l < 3 || !isName(words[0]) || words[2].Type != TokenLiteral {
+		return nil, sc.syntaxError("not enough arguments in notation declaration '%s'", d.read()).expecting("a name and an external identifier")
+	}
+
+	n.Name = words[0].Text
+	sc.Log.Info("ParseNotation ", n.Name)
+
+	switch {
+	case isKeyword(words[1], "PUBLIC"):
+		n.Public = true
+		n.PublicID = words[2].Value
+		n.PublicIDQuote = quote(words[2])
+	case isKeyword(words[1], "SYSTEM"):
+		n.System = true
+		n.SystemID = words[2].Value
+		n.SystemIDQuote = quote(words[2])
+	default:
+		return nil, sc.syntaxError("invalid external identifier in notation '%s'", n.Name).expecting("PUBLIC or SYSTEM")
+	}
+
+	if l > 3 && n.Public && words[3].Type == TokenLiteral {
+		n.SystemID = words[3].Value
+		n.SystemIDQuote = quote(words[3])
+		l--
+	}
+
+	if l > 3 {
+		return nil, sc.syntaxError("unexpected '%s' in notation '%s'", words[3].Text, n.Name).expecting("'>'")
+	}
+
+	return &n, nil
+}
+
+// ParseEntity Use the information in the declaration to return a pointer to a DTD.Entity
+// @ref https://www.w3.org/TR/xml11/#sec-entity-decl
+//
+// Entity Declaration
+// [70]   	EntityDecl ::=   	GEDecl | PEDecl
+// [71]   	GEDecl     ::=   	'<!ENTITY' S Name S EntityDef S? '>'
+// [72]   	PEDecl     ::=   	'<!ENTITY' S '%' S Name S PEDef S? '>'
+// [73]   	EntityDef  ::=   	EntityValue | (ExternalID NDataDecl?)
+// [74]   	PEDef	   ::=   	EntityValue | ExternalID
+// [75]     ExternalID ::=      'SYSTEM' S SystemLiteral | 'PUBLIC' S PubidLiteral S SystemLiteral
+// [76]     NDataDecl  ::=      S 'NDATA' S Name
+func (sc *DTDScanner) ParseEntity(d *declaration) (*DTD.Entity, error) {
+	var e DTD.Entity
+
+	e.Position = d.position()
+	words := d.words
+	l := len(words)
+	i := 0
+
+	sc.logDeclaration(d)
+
+	if i < l && words[i].Type == TokenPercent {
+		e.Parameter = true
+		i++
+	}
+
+	if i >= l || words[i].Type != TokenName {
+		return nil, sc.syntaxError("missing entity name in '%s'", d.read()).expecting("a name")
+	}
+
+	e.Name = words[i].Value
+	sc.Log.Info("ParseEntity ", e.Name)
+	i++
+
+	literal := func(k int) bool {
+		return k < l && words[k].Type == TokenLiteral
+	}
+
+	switch {
+	case i < l && isKeyword(words[i], "SYSTEM"):
+		e.System = true
+		e.IsExternal = true
+		if !literal(i + 1) {
+			return nil, sc.syntaxError("missing external identifier in entity '%s'", e.Name).expecting("a system literal")
+		}
+		e.Url = words[i+1].Value
+		e.UrlQuote = quote(words[i+1])
+		i += 2
+	case i < l && isKeyword(words[i], "PUBLIC"):
+		e.Public = true
+		e.IsExternal = true
+		if !literal(i+1) || !literal(i+2) {
+			return nil, sc.syntaxError("missing external identifier in entity '%s'", e.Name).expecting("a public and a system literal")
+		}
+		e.Value = words[i+1].Value
+		e.Quote = quote(words[i+1])
+		e.Url = words[i+2].Value
+		e.UrlQuote = quote(words[i+2])
+		i += 3
+	case literal(i):
+		e.Value = words[i].Value
+		e.Quote = quote(words[i])
+		i++
+	default:
+		return nil, sc.syntaxError("missing value in entity '%s'", e.Name).expecting("a literal, SYSTEM or PUBLIC")
+	}
+
+	// unparsed entities are declared with their notation
+	if e.IsExternal && !e.Parameter && i+1 < l && isKeyword(words[i], "NDATA") && words[i+1].Type == TokenName {
+		e.Notation = words[i+1].Value
+		i += 2
+	}
+
+	if i < l {
+		return nil, sc.syntaxError("unexpected '%s' in entity '%s'", words[i].Text, e.Name).expecting("'>'")
+	}
+
+	return &e, nil
+}
+
+// ParseAttlist Use the information in the declaration to return a pointer to a DTD.Attlist
+//
+// [52]   	AttlistDecl	   ::=   	'<!ATTLIST' S Name AttDef* S? '>'
+// [53]   	AttDef	   ::=   	S Name S AttType S DefaultDecl
+func (sc *DTDScanner) ParseAttlist(d *declaration) (*DTD.Attlist, error) {
+	var attlist DTD.Attlist
+
+	attlist.Position = d.position()
+	words := d.words
+	sc.logDeclaration(d)
+
+	if len(words) < 1 || !isName(words[0]) {
+		return nil, sc.syntaxError("missing element name in '%s'", d.read()).expecting("a name")
+	}
+
+	attlist.Name = words[0].Text
+	sc.Log.Info("ParseAttlist ", attlist.Name)
+	err := sc.parseAttributes(words[1:], &attlist.Attributes)
+
+	// the definitions are kept as is, they are parsed again once expanded
+	if errors.Is(err, errUnexpandedReference) {
+		sc.Log.Debugf("Attributes of '%s' hold parameter entity references, they are parsed after expansion", attlist.Name)
+		attlist.Attributes = nil
+		pos := words[1].Pos
+		attlist.Value = d.sourceAfter(words[0])
+		attlist.ValuePosition = &pos
+		return &attlist, nil
+	}
+	return &attlist, err
+}
+
+// ParseExpandedAttlist parse the attribute definitions kept in the Value of an attlist, see ParseAttlist
+// Parameter entity references are replaced by the replacement text returned by lookup,
+// the attributes read in it are located at the reference, the others keep their position.
+// When a reference is not declared, the definitions can't be parsed: Value is returned
+// with the declared references replaced.
+func ParseExpandedAttlist(a *DTD.Attlist, lookup func(name string) (string, bool), log *zap.SugaredLogger) (*DTD.Attlist, error) {
+	attlist := *a
+	attlist.Attributes = nil
+
+	pos := a.Position
+	if a.ValuePosition != nil {
+		pos = *a.ValuePosition
+	}
+
+	sc := NewScanner(pos.File, a.Value, log)
+	sc.lex.cur = DTD.Pos{File: pos.File, Offset: pos.Offset, Line: pos.Line, Column: pos.Column}
+
+	var words []Token
+	var value strings.Builder
+
+	for {
+		t, err := sc.next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Type == TokenEOF {
+			break
+		}
+
+		replacement, declared := "", false
+		if t.Type == TokenPEReference {
+			replacement, declared = lookup(t.Value)
+		}
+
+		if !declared {
+			value.WriteString(t.Text)
+			if t.Type != TokenWhitespace {
+				words = append(words, t)
+			}
+			continue
+		}
+
+		value.WriteString(" " + replacement + " ")
+		replaced, err := lexAt(t.Pos, replacement)
+
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, replaced...)
+	}
+
+	err := sc.parseAttributes(words, &attlist.Attributes)
+
+	if errors.Is(err, errUnexpandedReference) {
+		attlist.Attributes = nil
+		attlist.Value = strings.TrimSpace(value.String())
+		return &attlist, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	attlist.Value = ""
+	attlist.ValuePosition = nil
+	return &attlist, nil
+}
+
+// lexAt returns the words of s, they are all located at pos
+func lexAt(pos DTD.Pos, s string) ([]Token, error) {
+	var words []Token
+
+	lex := newLexer(pos.File, strings.NewReader(s))
+
+	for {
+		t, err := lex.next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t.Type {
+		case TokenEOF:
+			return words, nil
+		case TokenWhitespace:
+			continue
+		}
+
+		t.Pos = pos
+		words = append(words, t)
+	}
+}
+
+// parseAttributes Use the words of an attribute list declaration to return a pointer to *[]DTD.Attribute
+//
+// [54]   	AttType	      ::=   	StringType | TokenizedType | EnumeratedType
+// [57]   	EnumeratedType	   ::=   	NotationType | Enumeration
+// [60]   	DefaultDecl	   ::=   	'#REQUIRED' | '#IMPLIED' | (('#FIXED' S)? AttValue)
+func (sc *DTDScanner) parseAttributes(words []Token, attributes *[]DTD.Attribute) error {
+	i := 0
+	l := len(words)
+
+	sc.Log.Info("ParseAttributes")
+
+	for i < l {
+		var attr DTD.Attribute
+		first := words[i]
+
+		sc.Log.Debugf("Processing word: %s", first.Text)
+
+		// reference to an entity
+		if first.Type == TokenPEReference {
+			sc.Log.Debugf("- reference to an entity found: %s", first.Text)
+			attr.Value = first.Text
+			attr.IsEntity = true
+			attr.Position = first.Pos
+			*attributes = append(*attributes, attr)
+			i++
+			continue
+		}
+
+		if first.Type != TokenName {
+			return newSyntaxError(sc.Filepath, first.Pos, "unexpected '%s' in attribute list", first.Text).expecting("an attribute name")
+		}
+
+		// first word is always the attribute name
+		attr.Name = first.Value
+		sc.Log.Debugf("processing attribute: '%s'", attr.Name)
+		i++
+
+		if i >= l {
+			return sc.syntaxError("missing type for attribute '%s'", attr.Name).expecting("an attribute type")
+		}
+
+		// type is always in the second position
+		switch {
+		case words[i].Type == TokenPEReference:
+			return errUnexpandedReference
+		case words[i].Type == TokenOpenParen:
+			attr.Type = DTD.ENUM_ENUM
+		case words[i].Type == TokenName:
+			attr.Type = DTD.SeekAttributeType(words[i].Value)
+		}
+
+		if attr.Type == 0 {
+			return newSyntaxError(sc.Filepath, words[i].Pos, "unknown type '%s' for attribute '%s'", words[i].Text, attr.Name).expecting("CDATA, ID, IDREF, IDREFS, ENTITY, ENTITIES, NMTOKEN, NMTOKENS, NOTATION or an enumeration")
+		}
+
+		if attr.Type != DTD.ENUM_ENUM {
+			i++
+		}
+
+		sc.Log.Debugf("attribute type is %d", attr.Type)
+
+		// enumerated values are kept as the value until a default value is found
+		if attr.Type == DTD.ENUM_ENUM || attr.Type == DTD.ENUM_NOTATION {
+			enumeration, values, next, err := sc.readEnumeration(words, i, attr.Name)
+			if err != nil {
+				return err
+			}
+			attr.Value = enumeration
+			attr.Enumeration = values
+			i = next
+		}
+
+		if i >= l {
+			return sc.syntaxError("missing default value for attribute '%s'", attr.Name).expecting("#REQUIRED, #IMPLIED, #FIXED or a literal")
+		}
+
+		// default declaration
+		switch {
+		case words[i].Type == TokenPEReference:
+			return errUnexpandedReference
+		case words[i].Type == TokenKeyword && words[i].Value == "#REQUIRED":
+			attr.Required = true
+			attr.DefaultKind = DTD.DEFAULT_REQUIRED
+			sc.Log.Debug("REQUIRED Detected")
+		case words[i].Type == TokenKeyword && words[i].Value == "#IMPLIED":
+			attr.Implied = true
+			attr.DefaultKind = DTD.DEFAULT_IMPLIED
+			sc.Log.Debug("IMPLIED Detected")
+		case words[i].Type == TokenKeyword && words[i].Value == "#FIXED":
+			attr.Fixed = true
+			attr.DefaultKind = DTD.DEFAULT_FIXED
+			sc.Log.Debug("FIXED Detected")
+			i++
+			if i < l && words[i].Type == TokenPEReference {
+				return errUnexpandedReference
+			}
+			if i >= l || words[i].Type != TokenLiteral {
+				return sc.syntaxError("missing fixed value for attribute '%s'", attr.Name).expecting("a literal")
+			}
+			attr.DefaultValue = words[i].Value
+			attr.Value = attr.DefaultValue
+			attr.Quote = quote(words[i])
+		case words[i].Type == TokenLiteral:
+			attr.DefaultKind = DTD.DEFAULT_VALUE
+			attr.DefaultValue = words[i].Value
+			attr.Value = attr.DefaultValue
+			attr.Quote = quote(words[i])
+		default:
+			return newSyntaxError(sc.Filepath, words[i].Pos, "invalid default value '%s' for attribute '%s'", words[i].Text, attr.Name).expecting("#REQUIRED, #IMPLIED, #FIXED or a literal")
+		}
+
+		sc.Log.Debugf("Attribute value is %s", attr.Value)
+		attr.Position = span(first.Pos, words[i].Pos)
+		i++
+
+		sc.logAttribute(&attr)
+		*attributes = append(*attributes, attr)
+	}
+
+	return nil
+}
+
+// readEnumeration reads the enumerated values starting at words[i]
+// it returns the enumeration without white spaces, its values and the index of the following word
+// parameter entity references are kept as values
+//
+// [58]   	NotationType	   ::=   	'NOTATION' S '(' S? Name (S? '|' S? Name)* S? ')'
+// [59]   	Enumeration	   ::=   	'(' S? Nmtoken (S? '|' S? Nmtoken)* S? ')'
+func (sc *DTDScanner) readEnumeration(words []Token, i int, name string) (string, []string, int, error) {
+	var sb strings.Builder
+	var values []string
+
+	if i >= len(words) || words[i].Type != TokenOpenParen {
+		return "", nil, i, sc.syntaxError("missing enumeration for attribute '%s'", name).expecting("'('")
+	}
+
+	sb.WriteString(words[i].Text)
+
+	for i++; i < len(words); i++ {
+		sb.WriteString(words[i].Text)
+
+		switch words[i].Type {
+		case TokenCloseParen:
+			return sb.String(), values, i + 1, nil
+		case TokenName, TokenPEReference:
+			values = append(values, words[i].Text)
+		case TokenPipe:
+		default:
+			return "", nil, i, newSyntaxError(sc.Filepath, words[i].Pos, "unexpected '%s' in enumeration of attribute '%s'", words[i].Text, name).expecting("a name, '|' or ')'")
+		}
+	}
+
+	return "", nil, i, sc.syntaxError("unterminated enumeration for attribute '%s'", name).expecting("')'")
+}
+
+// logDeclaration helper function to log the words of a declaration
+func (sc *DTDScanner) logDeclaration(d *declaration) {
+	for i, w := range d.words {
+		sc.Log.Debugf(" - word [%d] '%s'", i, w.Text)
+	}
+}
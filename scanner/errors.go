@@ -0,0 +1,88 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// SyntaxError represents a malformed DTD block
+// Expected describes what was expected at the position of the error, when known.
+type SyntaxError struct {
+	File     string
+	Line     int
+	Column   int
+	Msg      string
+	Expected string
+}
+
+// Error implements error
+func (e *SyntaxError) Error() string {
+	if e.Expected != "" {
+		return fmt.Sprintf("%s:%d:%d: syntax error: %s, expected %s", e.File, e.Line, e.Column, e.Msg, e.Expected)
+	}
+	return fmt.Sprintf("%s:%d:%d: syntax error: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// expecting sets what was expected and returns the error
+func (e *SyntaxError) expecting(expected string) *SyntaxError {
+	e.Expected = expected
+	return e
+}
+
+// ErrorList represents the syntax errors found in a DTD
+type ErrorList []*SyntaxError
+
+// Error implements error, errors are reported one per line
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors of the list
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Err returns the list as an error, or nil if it is empty
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// syntaxError returns a SyntaxError located at the beginning of the current block
+func (sc *DTDScanner) syntaxError(format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		File:   sc.Filepath,
+		Line:   sc.CurrentLine,
+		Column: sc.CurrentColumn,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// newSyntaxError returns a SyntaxError located at pos
+func newSyntaxError(file string, pos DTD.Pos, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		File:   file,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
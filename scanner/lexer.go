@@ -0,0 +1,376 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// section tracks the start of a conditional section to detect ignored content
+const (
+	sectionNone = iota
+	sectionKeyword
+	sectionIgnore
+)
+
+// lexer splits a DTD read from an io.Reader into tokens
+// The input is read once through a buffer, a token is only allocated when it is complete.
+type lexer struct {
+	r        *bufio.Reader
+	file     string
+	cur      DTD.Pos // position of the next character
+	buf      []byte  // text of the token being read
+	section  int
+	ignoring bool
+	captures []*strings.Builder // source text recorders, see capture
+}
+
+// newLexer returns a lexer reading r, path locates the DTD in positions
+func newLexer(path string, r io.Reader) *lexer {
+	return &lexer{
+		r:    bufio.NewReaderSize(r, 64*1024),
+		file: path,
+		cur:  DTD.Pos{File: path, Line: 1, Column: 1},
+	}
+}
+
+// next returns the next token, TokenEOF is returned at the end of the input
+func (l *lexer) next() (Token, error) {
+	t, err := l.lex()
+
+	if err != nil {
+		return t, err
+	}
+
+	for _, c := range l.captures {
+		c.WriteString(t.Text)
+	}
+
+	l.track(t)
+	return t, nil
+}
+
+// capture starts recording the source text of the following tokens
+// the returned function stops the recording and returns the text
+func (l *lexer) capture() func() string {
+	var sb strings.Builder
+	l.captures = append(l.captures, &sb)
+
+	return func() string {
+		for i, c := range l.captures {
+			if c == &sb {
+				l.captures = append(l.captures[:i], l.captures[i+1:]...)
+				break
+			}
+		}
+		return sb.String()
+	}
+}
+
+// track follows the start of conditional sections, the content of an IGNORE
+// section is read as a single token
+func (l *lexer) track(t Token) {
+	switch {
+	case t.Type == TokenSectionOpen:
+		l.section = sectionKeyword
+	case t.Type == TokenWhitespace && l.section != sectionNone:
+	case t.Type == TokenName && t.Value == "IGNORE" && l.section == sectionKeyword:
+		l.section = sectionIgnore
+	case t.Type == TokenOpenBracket && l.section == sectionIgnore:
+		l.section = sectionNone
+		l.ignoring = true
+	default:
+		l.section = sectionNone
+	}
+}
+
+// lex reads a token
+func (l *lexer) lex() (Token, error) {
+	l.buf = l.buf[:0]
+	start := l.cur
+
+	if l.ignoring {
+		l.ignoring = false
+		return l.lexIgnored(start)
+	}
+
+	r, _, err := l.r.ReadRune()
+
+	if err == io.EOF {
+		return Token{Type: TokenEOF, Pos: l.span(start)}, nil
+	}
+
+	if err != nil {
+		return Token{}, err
+	}
+
+	if err := l.r.UnreadRune(); err != nil {
+		return Token{}, err
+	}
+
+	switch {
+	case isSpace(r):
+		l.readWhile(isSpace)
+		return l.emit(TokenWhitespace, start), nil
+
+	case l.hasPrefix("<!--"):
+		return l.lexUntil(TokenComment, start, "<!--", "-->", "comment")
+
+	case l.hasPrefix("<?"):
+		return l.lexUntil(TokenPI, start, "<?", "?>", "processing instruction")
+
+	case l.hasPrefix("<!["):
+		l.readString("<![")
+		return l.emit(TokenSectionOpen, start), nil
+
+	case l.hasPrefix("<!"):
+		l.readString("<!")
+		if l.readWhile(isNameChar) == 0 {
+			return Token{}, l.syntaxError(start, "missing declaration keyword after '<!'").expecting("ELEMENT, ATTLIST, ENTITY or NOTATION")
+		}
+		t := l.emit(TokenDeclOpen, start)
+		t.Value = t.Text[2:]
+		return t, nil
+
+	case l.hasPrefix("]]>"):
+		l.readString("]]>")
+		return l.emit(TokenSectionClose, start), nil
+
+	case r == '"' || r == '\'':
+		return l.lexLiteral(start, r)
+
+	case r == '%':
+		l.readRune()
+		if l.readWhile(isNameChar) == 0 {
+			return l.emit(TokenPercent, start), nil
+		}
+		if !l.hasPrefix(";") {
+			return Token{}, l.syntaxError(start, "unterminated parameter entity reference '%s'", string(l.buf)).expecting("';'")
+		}
+		l.readRune()
+		t := l.emit(TokenPEReference, start)
+		t.Value = t.Text[1 : len(t.Text)-1]
+		return t, nil
+
+	case r == '#':
+		l.readRune()
+		if l.readWhile(isNameChar) == 0 {
+			return Token{}, l.syntaxError(start, "missing keyword after '#'").expecting("PCDATA, REQUIRED, IMPLIED or FIXED")
+		}
+		return l.emit(TokenKeyword, start), nil
+
+	case isNameChar(r):
+		l.readWhile(isNameChar)
+		return l.emit(TokenName, start), nil
+	}
+
+	l.readRune()
+
+	if typ, ok := delimiters[r]; ok {
+		return l.emit(typ, start), nil
+	}
+
+	return Token{}, l.syntaxError(start, "unexpected character '%c'", r).expecting("a markup declaration, a name, a literal or a delimiter")
+}
+
+// delimiters maps single character tokens to their type
+var delimiters = map[rune]TokenType{
+	'>': TokenDeclClose,
+	'(': TokenOpenParen,
+	')': TokenCloseParen,
+	'[': TokenOpenBracket,
+	'|': TokenPipe,
+	',': TokenComma,
+	'?': TokenQuestion,
+	'*': TokenStar,
+	'+': TokenPlus,
+}
+
+// lexUntil reads a token delimited by open and close, the value is the text between them
+func (l *lexer) lexUntil(typ TokenType, start DTD.Pos, open string, close string, name string) (Token, error) {
+	l.readString(open)
+
+	for !l.hasPrefix(close) {
+		if _, ok := l.readRune(); !ok {
+			return Token{}, l.syntaxError(start, "unterminated %s", name).expecting("'" + close + "'")
+		}
+	}
+
+	l.readString(close)
+	t := l.emit(typ, start)
+	t.Value = t.Text[len(open) : len(t.Text)-len(close)]
+	return t, nil
+}
+
+// lexLiteral reads a literal delimited by quote
+// An unterminated literal ends at the first '>' followed by a newline or '<!' it holds,
+// the text following this point is read again so that the next declarations are recovered.
+func (l *lexer) lexLiteral(start DTD.Pos, quote rune) (Token, error) {
+	cut := -1
+	var cutPos DTD.Pos
+
+	l.readRune()
+
+	for {
+		if cut < 0 && (l.hasPrefix(">\n") || l.hasPrefix(">\r\n") || l.hasPrefix("<!")) {
+			cut, cutPos = len(l.buf), l.cur
+		}
+
+		r, ok := l.readRune()
+
+		if !ok {
+			if cut >= 0 {
+				l.rewind(cut, cutPos)
+			}
+			return Token{}, l.syntaxError(start, "unterminated literal").expecting("'" + string(quote) + "'")
+		}
+
+		if r == quote {
+			break
+		}
+	}
+
+	t := l.emit(TokenLiteral, start)
+	t.Value = t.Text[1 : len(t.Text)-1]
+	return t, nil
+}
+
+// lexIgnored reads the content of an IGNORE section up to the matching ']]>'
+// @ref https://www.w3.org/TR/xml11/#NT-ignoreSectContents
+func (l *lexer) lexIgnored(start DTD.Pos) (Token, error) {
+	depth := 0
+
+	for {
+		switch {
+		case l.hasPrefix("<!["):
+			depth++
+			l.readString("<![")
+		case l.hasPrefix("]]>") && depth == 0:
+			t := l.emit(TokenIgnored, start)
+			t.Value = t.Text
+			return t, nil
+		case l.hasPrefix("]]>"):
+			depth--
+			l.readString("]]>")
+		default:
+			if _, ok := l.readRune(); !ok {
+				return Token{}, l.syntaxError(start, "unterminated conditional section").expecting("']]>'")
+			}
+		}
+	}
+}
+
+// emit returns the token read since start, its value is its text
+func (l *lexer) emit(typ TokenType, start DTD.Pos) Token {
+	text := string(l.buf)
+	return Token{Type: typ, Text: text, Value: text, Pos: l.span(start)}
+}
+
+// span returns the position from start to the current position
+func (l *lexer) span(start DTD.Pos) DTD.Pos {
+	pos := start
+	pos.EndOffset = l.cur.Offset
+	pos.EndLine = l.cur.Line
+	pos.EndColumn = l.cur.Column
+	return pos
+}
+
+// readRune reads a character
+func (l *lexer) readRune() (rune, bool) {
+	r, size, err := l.r.ReadRune()
+
+	if err != nil {
+		return 0, false
+	}
+
+	l.consume(r, size)
+	return r, true
+}
+
+// rewind moves back to pos, the text of the token read from its offset n is read again
+func (l *lexer) rewind(n int, pos DTD.Pos) {
+	rest := append([]byte(nil), l.buf[n:]...)
+	l.buf = l.buf[:n]
+	l.cur = pos
+	l.r = bufio.NewReaderSize(io.MultiReader(bytes.NewReader(rest), l.r), 64*1024)
+}
+
+// readWhile reads characters while f is true and returns their number
+func (l *lexer) readWhile(f func(rune) bool) int {
+	n := 0
+
+	for {
+		r, size, err := l.r.ReadRune()
+
+		if err != nil {
+			return n
+		}
+
+		if !f(r) {
+			l.r.UnreadRune()
+			return n
+		}
+
+		l.consume(r, size)
+		n++
+	}
+}
+
+// consume adds r to the token being read and advances the position
+func (l *lexer) consume(r rune, size int) {
+	l.buf = utf8.AppendRune(l.buf, r)
+	l.cur.Offset += size
+
+	if r == '\n' {
+		l.cur.Line++
+		l.cur.Column = 1
+	} else {
+		l.cur.Column++
+	}
+}
+
+// readString reads s, s must be the next characters
+func (l *lexer) readString(s string) {
+	for range s {
+		l.readRune()
+	}
+}
+
+// hasPrefix tells if the next characters are s
+func (l *lexer) hasPrefix(s string) bool {
+	b, err := l.r.Peek(len(s))
+	return err == nil && string(b) == s
+}
+
+// syntaxError returns a SyntaxError located at pos
+func (l *lexer) syntaxError(pos DTD.Pos, format string, args ...interface{}) *SyntaxError {
+	return newSyntaxError(l.file, pos, format, args...)
+}
+
+// isSpace tells if r is a white space
+// [3] S ::= (#x20 | #x9 | #xD | #xA)+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// isNameChar tells if r can be part of a name
+// @ref https://www.w3.org/TR/xml11/#NT-NameChar
+func isNameChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r == '.', r == '-', r == '_', r == ':':
+		return true
+	}
+	return r >= 0x80 && !unicode.IsSpace(r)
+}
@@ -6,8 +6,10 @@
 package scanner
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
 
 	"go.uber.org/zap"
@@ -15,480 +17,442 @@ import (
 	"github.com/blefort/DTDParser/DTD"
 )
 
+// pseudoAttribute matches a pseudo attribute of a text declaration
+var pseudoAttribute = regexp.MustCompile(`^\s*([a-zA-Z]+)\s*=\s*("[^"]*"|'[^']*')`)
+
 // DTDScanner represents a DTD scanner
+// Blocks are built from the tokens of the lexer, the DTD is read once.
+// Malformed blocks are skipped, their syntax errors are collected in Errors.
 type DTDScanner struct {
-	Data         *bufio.Scanner
-	WithComments bool
-	Filepath     string
-	CurrentLine  int // first line of a block
-	CurrentChar  int
-	CharCount    int
-	LineCount    int  // line processed by the scanner
-	CRLF         bool // 0 linux / 1 dos
-	init         bool
-	scanResult   bool
-	Log          *zap.SugaredLogger
+	WithComments  bool
+	Filepath      string
+	CurrentLine   int // first line of a block
+	CurrentColumn int // first column of a block
+	Errors        ErrorList
+	Log           *zap.SugaredLogger
+	lex           *lexer
+	done          bool
+	pending       *Token  // token read ahead, see next
+	tokens        []Token // buffers of readDeclaration
+	words         []Token
 }
 
-// NewScanner returns a new DTD Scanner
+// NewScanner returns a new DTD Scanner reading the DTD s
 func NewScanner(path string, s string, log *zap.SugaredLogger) *DTDScanner {
+	return NewReaderScanner(path, strings.NewReader(s), log)
+}
+
+// NewReaderScanner returns a new DTD Scanner reading the DTD from r
+// r must provide UTF-8 text
+func NewReaderScanner(path string, r io.Reader, log *zap.SugaredLogger) *DTDScanner {
 	var scanner DTDScanner
-	scanner.Data = bufio.NewScanner(strings.NewReader(s))
-	scanner.Data.Split(bufio.ScanRunes)
 	scanner.Filepath = path
-	scanner.CharCount = 0
-	scanner.LineCount = 1
-	scanner.CRLF = false // assume Linux
-	scanner.init = false
-	scanner.scanResult = true
 	scanner.Log = log
+	scanner.lex = newLexer(path, r)
 	return &scanner
 }
 
-// Next Move to the next Block
+// NextBlock tells if blocks remain
 func (sc *DTDScanner) NextBlock() bool {
-	return sc.scanResult
-}
-
-// Next Move to the next character
-func (sc *DTDScanner) next() bool {
-	sc.CharCount++
-	sc.scanResult = sc.Data.Scan()
-	if sc.isEndOfLine() {
-		sc.LineCount++
-	}
-	return sc.scanResult
-}
-
-// Next Move to the next character
-func (sc *DTDScanner) Previous() bool {
-	sc.CharCount--
-	ret := sc.Data.Scan()
-	if sc.isEndOfLine() {
-		sc.LineCount++
-	}
-	return ret
+	return !sc.done
 }
 
-// Scan the string to find the next block
-func (sc *DTDScanner) Scan() (DTD.IDTDBlock, []*word, error) {
+// Scan the DTD to find the next block
+// A parameter entity reference between declarations is returned as a DTD.Reference.
+// A syntax error is added to Errors and the scanning resumes at the next markup
+// declaration, the returned error is a failure to read the DTD.
+// A nil block with a nil error is returned when no block remains
+func (sc *DTDScanner) Scan() (DTD.IDTDBlock, error) {
+	for {
+		t, err := sc.next()
+
+		if err != nil {
+			if err = sc.recover(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
 
-	// seek until next DTD block
-	s := sc.seekUntilNextBlock()
+		switch t.Type {
+		case TokenEOF:
+			sc.done = true
+			return nil, nil
+		case TokenWhitespace:
+			continue
+		}
 
-	// determine block type
-	sc.findDTDBlockType(s)
+		block, err := sc.scanBlock(t)
 
-	if s.DTDType == DTD.UNIDENTIFIED {
-		return nil, s.getWords(false), fmt.Errorf("could not identify DTD block at line %d", sc.LineCount)
-	}
+		if err != nil {
+			if err = sc.recover(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
 
-	if s.DTDType == DTD.COMMENT {
-		comment := sc.ParseComment(s)
-		return comment, s.getWords(false), nil
+		return block, nil
 	}
+}
 
-	if s.DTDType == DTD.ENTITY {
-		entity := sc.ParseEntity(s)
-		return entity, s.getWords(false), nil
+// next returns the token read ahead if any, or the next token of the lexer
+func (sc *DTDScanner) next() (Token, error) {
+	if t := sc.pending; t != nil {
+		sc.pending = nil
+		return *t, nil
 	}
+	return sc.lex.next()
+}
 
-	if s.DTDType == DTD.ELEMENT {
-		element := sc.ParseElement(s)
-		return element, s.getWords(false), nil
-	}
+// unread makes t the next token returned by next
+func (sc *DTDScanner) unread(t Token) {
+	sc.pending = &t
+}
 
-	if s.DTDType == DTD.NOTATION {
-		notation := sc.ParseNotation(s)
-		return notation, s.getWords(false), nil
-	}
+// recover adds a syntax error to Errors and skips the tokens up to the next
+// markup declaration, comment, processing instruction or end of conditional section.
+// Other errors stop the scanning and are returned.
+func (sc *DTDScanner) recover(err error) error {
+	var syntaxErr *SyntaxError
 
-	if s.DTDType == DTD.ATTLIST {
-		attlist := sc.ParseAttlist(s)
-		sc.logOutputAttributes(&attlist.Attributes)
-		return attlist, s.getWords(false), nil
+	if !errors.As(err, &syntaxErr) {
+		sc.done = true
+		return err
 	}
 
-	return nil, s.getWords(false), fmt.Errorf("could not identify DTD block at line %d", sc.LineCount)
-
-}
-
-// ParseComment Use the information in the sentence to return a pointer to a DTD.Comment
-func (sc *DTDScanner) ParseComment(s *sentence) *DTD.Comment {
-	var c DTD.Comment
-	sc.Log.Info("Comment found line ", sc.CurrentLine)
-	c.Value = s.readSequence()
-	return &c
-}
-
-// ParseEntity Use the information in the sentence to return a pointer to a DTD.Element
-// @ref https://www.w3.org/TR/xml11/#elemdecls
-//
-// Element Declaration
-// [45]   	elementdecl	   ::=   	'<!ELEMENT' S Name S contentspec S? '>'	[VC: Unique Element Type Declaration]
-// [46]   	contentspec	   ::=   	'EMPTY' | 'ANY' | Mixed | children
-//
-func (sc *DTDScanner) ParseElement(s *sentence) *DTD.Element {
-	var e DTD.Element
+	sc.Log.Warnf("%v", syntaxErr)
+	sc.Errors = append(sc.Errors, syntaxErr)
 
-	words := s.getWords(true)
-	l := len(words)
+	for {
+		t, err := sc.next()
 
-	if len(words) < 2 {
-		sc.Log.Errorf("not enough arguments in sentence '%s'. Count was (%d)", s.sequence, len(words))
-	}
-	e.Name = words[1].Read()
+		if err != nil {
+			if errors.As(err, &syntaxErr) {
+				// the lexer always moves forward, errors in skipped text are not reported
+				continue
+			}
+			sc.done = true
+			return err
+		}
 
-	for i := 2; i < l; i++ {
-		e.Value = e.Value + " " + words[i].Read()
+		switch t.Type {
+		case TokenEOF, TokenDeclOpen, TokenSectionOpen, TokenSectionClose, TokenComment, TokenPI:
+			sc.unread(t)
+			return nil
+		}
 	}
-
-	sc.Log.Info("ParseElement ", e.Name)
-	return &e
 }
 
-// ParseNotation Use the information in the sentence to return a pointer to a DTD.Notation
-// @ref https://www.w3.org/TR/xml11/#Notations
-//
-// Element Declaration
-//
-// [82]  NotationDec ::= '<!NOTATION' S Name S (ExternalID | PublicID) S? '>'  [VC: Unique Notation Name]
-// [83]  PublicID    ::= 'PUBLIC' S PubidLiteral
-//
-func (sc *DTDScanner) ParseNotation(s *sentence) *DTD.Notation {
-	var n DTD.Notation
+// scanBlock returns the block starting with the token t
+func (sc *DTDScanner) scanBlock(t Token) (DTD.IDTDBlock, error) {
+	sc.CurrentLine = t.Pos.Line
+	sc.CurrentColumn = t.Pos.Column
+
+	switch t.Type {
+	case TokenComment:
+		return sc.ParseComment(t), nil
+
+	case TokenPI:
+		if target, _, _ := splitProcessingInstruction(t.Text); target == "xml" {
+			decl, err := sc.ParseXMLDecl(t)
+			if err != nil {
+				return nil, err
+			}
+			return decl, nil
+		}
+		pi, err := sc.ParseProcessingInstruction(t)
+		if err != nil {
+			return nil, err
+		}
+		return pi, nil
 
-	words := s.getWords(true)
+	case TokenSectionOpen:
+		return sc.ParseConditionalSection(t)
 
-	l := len(words)
+	case TokenPEReference:
+		return sc.ParseReference(t), nil
 
-	for _, w := range words {
+	case TokenDeclOpen:
+		d, err := sc.readDeclaration(t)
 
-		if w.Read() == "PUBLIC" {
-			n.Public = true
+		if err != nil {
+			return nil, err
 		}
 
-		if w.Read() == "SYSTEM" {
-			n.System = true
+		if sc.debugging() {
+			sc.Log.Debugf("declaration is '%s'", d.read())
 		}
 
-	}
-
-	n.Name = words[1].Read()
-	sc.Log.Info("ParseNotation ", n.Name)
+		switch t.Value {
+		case "ELEMENT":
+			element, err := sc.ParseElement(d)
+			if err != nil {
+				return nil, err
+			}
+			return element, nil
+		case "ATTLIST":
+			attlist, err := sc.ParseAttlist(d)
+			if err != nil {
+				return nil, err
+			}
+			sc.logOutputAttributes(&attlist.Attributes)
+			return attlist, nil
+		case "ENTITY":
+			entity, err := sc.ParseEntity(d)
+			if err != nil {
+				return nil, err
+			}
+			return entity, nil
+		case "NOTATION":
+			notation, err := sc.ParseNotation(d)
+			if err != nil {
+				return nil, err
+			}
+			return notation, nil
+		}
 
-	if l > 3 && n.Public {
-		n.PublicID = words[3].Read()
+		return nil, sc.syntaxError("could not identify DTD block '%s'", d.read()).expecting("ELEMENT, ATTLIST, ENTITY or NOTATION")
 	}
 
-	if l > 3 && n.System {
-		n.SystemID = words[3].Read()
-	}
+	return nil, newSyntaxError(sc.Filepath, t.Pos, "unexpected '%s' outside of a declaration", t.Text).expecting("'<!'")
+}
 
-	if l > 4 {
-		n.SystemID = words[4].Read()
-	}
+// ParseComment Use the comment token to return a pointer to a DTD.Comment
+func (sc *DTDScanner) ParseComment(t Token) *DTD.Comment {
+	var c DTD.Comment
+	sc.Log.Info("Comment found line ", sc.CurrentLine)
+	c.Value = strings.Trim(t.Value, "!- ")
+	c.Position = t.Pos
+	return &c
+}
 
-	return &n
+// ParseReference Use the parameter entity reference token to return a pointer to a DTD.Reference
+// @ref https://www.w3.org/TR/xml11/#NT-PEReference
+func (sc *DTDScanner) ParseReference(t Token) *DTD.Reference {
+	var r DTD.Reference
+	sc.Log.Info("ParseReference ", t.Value)
+	r.Name = t.Value
+	r.Position = t.Pos
+	return &r
 }
 
-// ParseEntity Use the information in the sentence to return a pointer to a DTD.Entity
-// @ref https://www.w3.org/TR/xml11/#sec-entity-decl
-//
-// Entity Declaration
-// [70]   	EntityDecl ::=   	GEDecl | PEDecl
-// [71]   	GEDecl     ::=   	'<!ENTITY' S Name S EntityDef S? '>'
-// [72]   	PEDecl     ::=   	'<!ENTITY' S '%' S Name S PEDef S? '>'
-// [73]   	EntityDef  ::=   	EntityValue | (ExternalID NDataDecl?)
-// [74]   	PEDef	   ::=   	EntityValue | ExternalID
+// ParseProcessingInstruction Use the token to return a pointer to a DTD.ProcessingInstruction
+// @ref https://www.w3.org/TR/xml11/#sec-pi
 //
-func (sc *DTDScanner) ParseEntity(s *sentence) *DTD.Entity {
-	var e DTD.Entity
-
-	words := s.getWords(true)
-	idx := 0
-	pidx := 1
-
-	sc.logWords(&words)
+// [16] PI       ::= '<?' PITarget (S (Char* - (Char* '?>' Char*)))? '?>'
+// [17] PITarget ::= Name - (('X' | 'x') ('M' | 'm') ('L' | 'l'))
+func (sc *DTDScanner) ParseProcessingInstruction(t Token) (*DTD.ProcessingInstruction, error) {
+	var pi DTD.ProcessingInstruction
 
-	for i, w := range words {
+	pi.Position = t.Pos
+	target, value, err := splitProcessingInstruction(t.Text)
 
-		if w.Read() == "%" {
-			e.Parameter = true
-			pidx = i + 1
-		}
-
-		if w.Read() == "PUBLIC" {
-			e.Public = true
-			e.IsExternal = true
-			idx = i
-		}
-
-		if w.Read() == "SYSTEM" {
-			e.System = true
-			e.IsExternal = true
-			idx = i
-		}
+	if err != nil {
+		return nil, sc.syntaxError("%v", err)
 	}
 
-	e.Name = words[pidx].Read()
-	sc.Log.Info("ParseEntity ", e.Name)
-
-	if e.System {
-		e.Url = words[idx+1].Read()
-	} else if e.Public {
-		e.Url = words[idx+2].Read()
-		e.Value = words[idx+1].Read()
-	} else if e.Parameter {
-		e.Value = words[3].Read()
-	} else {
-		e.Value = words[len(words)-1].Read()
+	if strings.EqualFold(target, "xml") {
+		return nil, sc.syntaxError("processing instruction target '%s' is reserved", target)
 	}
 
-	return &e
+	pi.Target = target
+	pi.Value = value
+
+	sc.Log.Info("ParseProcessingInstruction ", pi.Target)
+	return &pi, nil
 }
 
-// ParseAttlist Use the information in the sentence to return a pointer to a DTD.Attlist
+// ParseXMLDecl Use the token to return a pointer to a DTD.XMLDecl
+// @ref https://www.w3.org/TR/xml11/#sec-TextDecl
 //
-// [52]   	AttlistDecl	   ::=   	'<!ATTLIST' S Name AttDef* S? '>'
-// [53]   	AttDef	   ::=   	S Name S AttType S DefaultDecl
+// [77] TextDecl     ::= '<?xml' VersionInfo? EncodingDecl S? '?>'
+// [24] VersionInfo  ::= S 'version' Eq ("'" VersionNum "'" | '"' VersionNum '"')
+// [80] EncodingDecl ::= S 'encoding' Eq ('"' EncName '"' | "'" EncName "'" )
 //
-func (sc *DTDScanner) ParseAttlist(s *sentence) *DTD.Attlist {
-	var attlist DTD.Attlist
-
-	words := s.getWords(true)
-	sc.logWords(&words)
-	attlist.Name = words[1].Read()
-	sc.Log.Info("ParseAttlist ", attlist.Name)
-	sc.parseAttributes(words[2:len(words)], &attlist.Attributes)
-	return &attlist
-}
-
-// parseAttributes Use the information in the sentence.words to return a pointer to *[]DTD.Attribute
-func (sc *DTDScanner) parseAttributes(words []*word, attributes *[]DTD.Attribute) {
-
-	i := -1
-	l := len(words)
-
-	sc.Log.Info("ParseAttributes")
+// The declaration is allowed only at the start of the DTD
+func (sc *DTDScanner) ParseXMLDecl(t Token) (*DTD.XMLDecl, error) {
+	var x DTD.XMLDecl
 
-	sc.logWords(&words)
+	x.Position = t.Pos
 
-	nextWord := func(i *int, l int) bool {
-		if *i+1 < l {
-			*i++
-			sc.Log.Debugf("i: %d", *i)
-			return true
-		}
-		return false
+	if x.Position.Offset != 0 {
+		return nil, sc.syntaxError("XML declaration is allowed only at the start of the DTD")
 	}
 
-	for nextWord(&i, l) {
-
-		var attr DTD.Attribute
+	_, value, err := splitProcessingInstruction(t.Text)
 
-		// instruction varies depending the type, we don't know in advance
-		sc.Log.Debugf("Processing word: %s", words[i].Read())
-		// reference to an entity
-		if words[i].Read()[0:1] == "%" {
-			sc.Log.Debugf("- reference to an entity found: %s", words[i].Read())
-			attr.Value = words[i].Read()
-			attr.IsEntity = true
-			*attributes = append(*attributes, attr)
-			continue
-		}
+	if err != nil {
+		return nil, sc.syntaxError("%v", err)
+	}
 
-		// // first word is always the attribute name
-		attr.Name = words[i].Read()
-		sc.Log.Debugf("processing attribute: '%s'", attr.Name)
+	for strings.TrimSpace(value) != "" {
+		m := pseudoAttribute.FindStringSubmatch(value)
 
-		if !nextWord(&i, l) {
-			sc.Log.Fatalf("Not enough arguments to loop through attributes i:%d", i)
+		if m == nil {
+			return nil, sc.syntaxError("invalid XML declaration '%s'", t.Text).expecting("version, encoding or standalone")
 		}
 
-		// CASE 2
-		// The others words are processed by group of 3 to 4 depending the type
-
-		// // Type is always in the second position
-		attr.Type = DTD.SeekAttributeType(words[i].Read())
-		sc.Log.Debugf("attribute type is %d", attr.Type)
-
-		if attr.Type == DTD.CDATA { // 20
-			nextWord(&i, l)
-			sc.checkDefaultValue(words, &i, &attr)
-		} else if attr.Type == DTD.TOKEN_ID ||
-			attr.Type == DTD.TOKEN_IDREF ||
-			attr.Type == DTD.TOKEN_IDREFS ||
-			attr.Type == DTD.TOKEN_ENTITY ||
-			attr.Type == DTD.TOKEN_ENTITIES ||
-			attr.Type == DTD.TOKEN_NMTOKENS {
-			nextWord(&i, l)
-			sc.checkDefaultValue(words, &i, &attr)
-		} else if attr.Type == DTD.TOKEN_NMTOKEN {
-			nextWord(&i, l)
-			sc.checkDefaultValue(words, &i, &attr)
-		} else if attr.Type == DTD.ENUM_NOTATION {
-			nextWord(&i, l)
-			sc.checkDefaultValue(words, &i, &attr)
-		} else if attr.Type == DTD.ENUM_ENUM {
-			sc.checkDefaultValue(words, &i, &attr)
-		} else {
-			sc.Log.Fatalf("unmanaged attribute type %d", attr.Type)
+		v := m[2][1 : len(m[2])-1]
+
+		switch m[1] {
+		case "version":
+			x.Version = v
+		case "encoding":
+			x.Encoding = v
+		case "standalone":
+			x.Standalone = v
+		default:
+			return nil, sc.syntaxError("unknown pseudo attribute '%s' in XML declaration", m[1]).expecting("version, encoding or standalone")
 		}
 
-		sc.logAttribute(&attr)
-		*attributes = append(*attributes, attr)
-
+		value = value[len(m[0]):]
 	}
+
+	sc.Log.Info("ParseXMLDecl ", x.GetValue())
+	return &x, nil
 }
 
-// heckDefaultValue
-func (sc *DTDScanner) checkDefaultValue(w []*word, i *int, attr *DTD.Attribute) {
+// splitProcessingInstruction returns the target and the instruction of a processing instruction
+func splitProcessingInstruction(text string) (string, string, error) {
 
-	if *i > len(w)-1 {
-		return
+	if len(text) < 4 || !strings.HasPrefix(text, "<?") || !strings.HasSuffix(text, "?>") {
+		return "", "", fmt.Errorf("processing instruction '%s' must end with '?>'", text)
 	}
 
-	sc.checkFixed(w, i, attr)
+	content := text[2 : len(text)-2]
+	target := content
+	value := ""
 
-	if attr.Fixed {
-		attr.Value = w[*i].Read()
-		sc.Log.Debugf("Attribute value is %s", attr.Value)
-		return
+	if idx := strings.IndexAny(content, " \t\r\n"); idx >= 0 {
+		target = content[:idx]
+		value = strings.TrimLeft(content[idx:], " \t\r\n")
 	}
 
-	// Required and implied appears to be always the last value
-	sc.checkRequired(w, i, attr)
-	if attr.Required {
-		return
+	if target == "" {
+		return "", "", fmt.Errorf("processing instruction '%s' has no target", text)
 	}
 
-	sc.checkImplied(w, i, attr)
-	if attr.Implied {
-		return
-	}
+	return target, value, nil
+}
+
+// ParseConditionalSection Use the tokens following '<![' to return a pointer to a DTD.ConditionalSection
+// @ref https://www.w3.org/TR/xml11/#sec-condition-sect
+//
+// [61]   	conditionalSect	   ::=   	includeSect | ignoreSect
+// [62]   	includeSect	       ::=   	'<![' S? 'INCLUDE' S? '[' extSubsetDecl ']]>'
+// [63]   	ignoreSect	       ::=   	'<![' S? 'IGNORE' S? '[' ignoreSectContents* ']]>'
+//
+// Declarations and parameter entity references of the section are scanned unless the keyword is IGNORE.
+func (sc *DTDScanner) ParseConditionalSection(open Token) (*DTD.ConditionalSection, error) {
+	var c DTD.ConditionalSection
 
-	if !attr.Fixed && !attr.Required && !attr.Implied {
-		attr.Value = w[*i].Read()
-		sc.Log.Debugf("Attribute value is %s", attr.Value)
-		*i++
+	t, err := sc.nextSignificant()
+
+	if err != nil {
+		return nil, err
 	}
 
-	if *i < len(w) {
-		sc.Log.Debugf("Second round")
-		sc.checkDefaultValue(w, i, attr)
+	if t.Type != TokenName && t.Type != TokenPEReference {
+		return nil, sc.syntaxError("invalid conditional section keyword '%s'", t.Text).expecting("INCLUDE, IGNORE or a parameter entity reference")
 	}
 
-}
+	c.Keyword = t.Text
 
-// checkRequired
-func (sc *DTDScanner) checkRequired(w []*word, i *int, attr *DTD.Attribute) {
-	if *i > len(w)-1 {
-		return
-	}
-	if w[*i].Read() == "#REQUIRED" {
-		attr.Required = true
-		sc.Log.Debug("REQUIRED Detected")
+	if c.Keyword != "INCLUDE" && c.Keyword != "IGNORE" && !c.IsParameter() {
+		return nil, sc.syntaxError("invalid conditional section keyword '%s'", c.Keyword).expecting("INCLUDE, IGNORE or a parameter entity reference")
 	}
-}
 
-// checkImplied
-func (sc *DTDScanner) checkImplied(w []*word, i *int, attr *DTD.Attribute) {
-	if *i > len(w)-1 {
-		return
-	}
-	if w[*i].Read() == "#IMPLIED" {
-		attr.Implied = true
-		sc.Log.Debug("IMPLIED Detected")
+	if t, err = sc.nextSignificant(); err != nil {
+		return nil, err
 	}
-}
 
-// checkFixed
-func (sc *DTDScanner) checkFixed(w []*word, i *int, attr *DTD.Attribute) {
-	if *i > len(w)-1 {
-		return
+	if t.Type != TokenOpenBracket {
+		return nil, sc.syntaxError("missing '[' after conditional section keyword").expecting("'['")
 	}
-	if w[*i].Read() == "#FIXED" {
-		*i++
-		attr.Fixed = true
-		sc.Log.Debug("FIXED Detected")
-	}
-}
 
-// isEndOfLine Identitfy a carriage return
-func (sc *DTDScanner) isEndOfLine() bool {
+	sc.Log.Info("ParseConditionalSection ", c.Keyword)
 
-	if sc.Data.Text() == "\n" {
-		return true
-	}
-	if sc.Data.Text() == "\r" {
-		sc.CRLF = true
-		return false
-	}
-	return false
-}
+	content := sc.lex.capture()
 
-// seekUntilNextBlock return string until next block is found
-func (sc *DTDScanner) seekUntilNextBlock() *sentence {
+	for {
+		t, err := sc.next()
 
-	//	var contentPosition int
-	sentence := newsentence("<", ">", sc.Log)
+		if err == nil && t.Type == TokenEOF {
+			err = newSyntaxError(sc.Filepath, open.Pos, "unterminated conditional section").expecting("']]>'")
+			sc.unread(t)
+			content()
+			return nil, err
+		}
 
-	sc.CurrentLine = sc.LineCount
-	sc.Log.Debugf(fmt.Sprintf("start scanning sentence with char '%s'", sc.Data.Text()))
+		if err != nil {
+			// malformed blocks of the section are skipped
+			if err = sc.recover(err); err != nil {
+				content()
+				return nil, err
+			}
+			continue
+		}
 
-	sentence.scan(sc.Data.Text())
+		switch t.Type {
 
-	for sc.next() {
+		case TokenSectionClose:
+			c.Content = strings.TrimSuffix(content(), t.Text)
+			c.Position = span(open.Pos, t.Pos)
+			return &c, nil
 
-		if sentence.scan(sc.Data.Text()) {
-			sentence.read()
-			sc.next()
-			return sentence
+		case TokenWhitespace, TokenIgnored:
+			continue
 		}
-	}
 
-	return sentence
-}
-
-// findDTDBlockType Determine DTD type with the first word of the sentence
-func (sc *DTDScanner) findDTDBlockType(s *sentence) {
+		block, err := sc.scanBlock(t)
 
-	words := s.getWords(true)
+		if err != nil {
+			if err = sc.recover(err); err != nil {
+				content()
+				return nil, err
+			}
+			continue
+		}
 
-	if len(words) == 0 {
-		return
+		c.Blocks = append(c.Blocks, block)
 	}
+}
+
+// nextSignificant returns the next token that is not a white space
+func (sc *DTDScanner) nextSignificant() (Token, error) {
+	for {
+		t, err := sc.next()
 
-	if len(words[0].Read()) > 3 && words[0].Read()[0:4] == "<!--" {
-		s.DTDType = DTD.COMMENT
-	} else if words[0].Read() == "<!ATTLIST" {
-		s.DTDType = DTD.ATTLIST
-	} else if words[0].Read() == "<!ELEMENT" {
-		s.DTDType = DTD.ELEMENT
-	} else if words[0].Read() == "<!NOTATION" {
-		s.DTDType = DTD.NOTATION
-	} else if words[0].Read() == "<!ENTITY" {
-		s.DTDType = DTD.ENTITY
+		if err != nil || t.Type != TokenWhitespace {
+			return t, err
+		}
 	}
-	sc.Log.Debugf(fmt.Sprintf("block type is (%d)", s.DTDType))
+}
+
+// span returns the position from the start of first to the end of last
+func span(first DTD.Pos, last DTD.Pos) DTD.Pos {
+	pos := first
+	pos.EndOffset = last.EndOffset
+	pos.EndLine = last.EndLine
+	pos.EndColumn = last.EndColumn
+	return pos
 }
 
 // logOutputAttributes helper function to output attributes in the log
 func (sc *DTDScanner) logAttribute(attr *DTD.Attribute) {
-	sc.Log.Infof(fmt.Sprintf(" - attribute: '%s'", attr.Render()))
+	sc.Log.Infof(" - attribute: '%s'", attr.Render())
 }
 
 // logOutputAttributes helper function to output attributes in the log
 func (sc *DTDScanner) logOutputAttributes(attributes *[]DTD.Attribute) {
+	if !sc.debugging() {
+		return
+	}
 	for i, attr := range *attributes {
-		sc.Log.Debugf(fmt.Sprintf(" - attribute (%d): '%s'", i, attr.Render()))
+		sc.Log.Debugf(" - attribute (%d): '%s'", i, attr.Render())
 	}
 }
 
-// logWords helper function to log words
-func (sc *DTDScanner) logWords(words *[]*word) {
-	for i, w := range *words {
-		sc.Log.Debugf(fmt.Sprintf(" - word [%d] '%s'", i, w.Read()))
-	}
+// debugging tells if debug messages are logged, to avoid building them otherwise
+func (sc *DTDScanner) debugging() bool {
+	return sc.Log.Desugar().Core().Enabled(zap.DebugLevel)
 }
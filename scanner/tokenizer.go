@@ -0,0 +1,121 @@
+// Copyright 2019 Bertrand Lefort. All rights reserved.
+// Use of this source code is governed under MIT License
+// that can be found in the LICENSE file.
+
+// Package scanner allows to extract information from the DTD and create corresponding DTD structs
+package scanner
+
+import (
+	"io"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// TokenType identifies the lexical class of a Token
+type TokenType int
+
+const (
+	TokenEOF          TokenType = iota
+	TokenWhitespace             // S
+	TokenComment                // <!-- ... -->
+	TokenPI                     // <? ... ?>
+	TokenDeclOpen               // <!ELEMENT, <!ATTLIST, <!ENTITY, <!NOTATION
+	TokenDeclClose              // >
+	TokenSectionOpen            // <![
+	TokenSectionClose           // ]]>
+	TokenIgnored                // content of an IGNORE conditional section
+	TokenName                   // Name or Nmtoken
+	TokenKeyword                // #PCDATA, #REQUIRED, #IMPLIED, #FIXED
+	TokenLiteral                // "..." or '...'
+	TokenPEReference            // %name;
+	TokenPercent                // % of a parameter entity declaration
+	TokenOpenParen              // (
+	TokenCloseParen             // )
+	TokenOpenBracket            // [
+	TokenPipe                   // |
+	TokenComma                  // ,
+	TokenQuestion               // ?
+	TokenStar                   // *
+	TokenPlus                   // +
+)
+
+// tokenNames holds the names of the token types
+var tokenNames = [...]string{
+	TokenEOF:          "EOF",
+	TokenWhitespace:   "Whitespace",
+	TokenComment:      "Comment",
+	TokenPI:           "PI",
+	TokenDeclOpen:     "DeclOpen",
+	TokenDeclClose:    "DeclClose",
+	TokenSectionOpen:  "SectionOpen",
+	TokenSectionClose: "SectionClose",
+	TokenIgnored:      "Ignored",
+	TokenName:         "Name",
+	TokenKeyword:      "Keyword",
+	TokenLiteral:      "Literal",
+	TokenPEReference:  "PEReference",
+	TokenPercent:      "Percent",
+	TokenOpenParen:    "OpenParen",
+	TokenCloseParen:   "CloseParen",
+	TokenOpenBracket:  "OpenBracket",
+	TokenPipe:         "Pipe",
+	TokenComma:        "Comma",
+	TokenQuestion:     "Question",
+	TokenStar:         "Star",
+	TokenPlus:         "Plus",
+}
+
+// String returns the name of the token type
+func (t TokenType) String() string {
+	if t < 0 || int(t) >= len(tokenNames) {
+		return "Unknown"
+	}
+	return tokenNames[t]
+}
+
+// Token is a lexical unit of a DTD
+// Text is the source text of the token, Value its meaningful part: the keyword
+// of a declaration, the name of a reference, the content of a literal or of a comment.
+type Token struct {
+	Type  TokenType
+	Text  string
+	Value string
+	Pos   DTD.Pos
+}
+
+// Tokenizer splits a DTD into tokens following the lexing rules of the parser
+// White spaces and comments are skipped unless requested.
+// Concatenating the Text of all the tokens gives back the DTD when both are kept.
+type Tokenizer struct {
+	WithWhitespace bool
+	WithComments   bool
+	lex            *lexer
+}
+
+// NewTokenizer returns a Tokenizer reading the DTD from r
+// r must provide UTF-8 text, path locates the DTD in the positions of the tokens
+func NewTokenizer(path string, r io.Reader) *Tokenizer {
+	return &Tokenizer{lex: newLexer(path, r)}
+}
+
+// Next returns the next token
+// A token of type TokenEOF is returned at the end of the DTD, a *SyntaxError
+// is returned if the DTD cannot be split into tokens.
+func (tk *Tokenizer) Next() (Token, error) {
+	for {
+		t, err := tk.lex.next()
+
+		if err != nil {
+			return Token{}, err
+		}
+
+		switch {
+		case t.Type == TokenWhitespace && !tk.WithWhitespace:
+			continue
+		case t.Type == TokenComment && !tk.WithComments:
+			continue
+		}
+
+		return t, nil
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// TestSchema Test the schema model of a DTD and of its modules
+func TestSchema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.dtd": {Data: []byte("<!ENTITY % mod SYSTEM \"mod.ent\">\n" +
+			"%mod;\n" +
+			"<!ELEMENT doc (title, para*)>\n" +
+			"<!ATTLIST doc id ID #REQUIRED>\n" +
+			"<!ENTITY copy \"&#xA9;\">\n" +
+			"<!ENTITY copy \"(c)\">\n")},
+		"mod.ent": {Data: []byte("<!ENTITY % inline SYSTEM \"inline.ent\">\n" +
+			"<![INCLUDE[ %inline; ]]>\n" +
+			"<!ELEMENT para (#PCDATA)>\n" +
+			"<!ATTLIST doc lang CDATA #IMPLIED>\n" +
+			"<!NOTATION png SYSTEM \"image/png\">\n")},
+		"inline.ent": {Data: []byte("<!ELEMENT title (#PCDATA)>\n")},
+	}
+
+	p := newParser(t.TempDir())
+	p.IgnoreExtRefIssue = false
+	p.SetFS(fsys)
+
+	schema, err := p.ParseSchema("main.dtd")
+
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	t.Run("Check elements", checkIntValue(len(schema.Elements), 3, schema.Elements, nil))
+	t.Run("Check notations", checkIntValue(len(schema.Notations), 1, schema.Notations, nil))
+	t.Run("Check parameter entities", checkIntValue(len(schema.ParameterEntities), 2, schema.ParameterEntities, nil))
+	t.Run("Check attribute lists", checkIntValue(len(schema.Attlists["doc"]), 2, schema.Attlists, nil))
+	t.Run("Check element lookup", checkStrValue(schema.Elements["title"].Value, " (#PCDATA)", schema.Elements["title"], nil))
+	t.Run("Check first entity", checkStrValue(schema.Entities["copy"].Value, "&#xA9;", schema.Entities["copy"], nil))
+
+	// document order, modules inlined where they are referenced
+	var elements []string
+
+	for _, block := range schema.Blocks {
+		if e, ok := block.(*DTD.Element); ok {
+			elements = append(elements, e.Name)
+		}
+	}
+
+	t.Run("Check order", checkStrValue(strings.Join(elements, ","), "title,para,doc", elements, nil))
+
+	// include tree
+	var files []string
+
+	for _, m := range schema.Modules() {
+		files = append(files, m.File)
+	}
+
+	t.Run("Check modules", checkStrValue(strings.Join(files, ","), "main.dtd,mod.ent,inline.ent", files, nil))
+	t.Run("Check include entity", checkStrValue(schema.Root.Includes[0].Entity.Name, "mod", schema.Root, nil))
+
+	mod := schema.Root.Includes[0].Module
+	t.Run("Check module blocks", checkIntValue(len(mod.Blocks), 5, mod, nil))
+	t.Run("Check nested include", checkStrValue(mod.Includes[0].Entity.Name, "inline", mod, nil))
+}
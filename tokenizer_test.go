@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+	"github.com/blefort/DTDParser/scanner"
+)
+
+// tokenize returns the tokens read by tk
+func tokenize(t *testing.T, tk *scanner.Tokenizer) []scanner.Token {
+	var tokens []scanner.Token
+
+	for {
+		tok, err := tk.Next()
+
+		if err != nil {
+			t.Fatalf("Tokenizing failed: %v", err)
+		}
+
+		if tok.Type == scanner.TokenEOF {
+			return tokens
+		}
+
+		tokens = append(tokens, tok)
+	}
+}
+
+// TestTokenizerRoundTrip Test that the text of the tokens gives back the DTD
+func TestTokenizerRoundTrip(t *testing.T) {
+	src, err := os.ReadFile("tests/conditional.dtd")
+
+	if err != nil {
+		t.Fatalf("Reading failed: %v", err)
+	}
+
+	tk := scanner.NewTokenizer("conditional.dtd", strings.NewReader(string(src)))
+	tk.WithWhitespace = true
+	tk.WithComments = true
+
+	var sb strings.Builder
+
+	for _, tok := range tokenize(t, tk) {
+		sb.WriteString(tok.Text)
+	}
+
+	if sb.String() != string(src) {
+		t.Errorf("Tokens text '%s' differs from the DTD '%s'", sb.String(), string(src))
+	}
+}
+
+// TestTokenizer Test the types, values and positions of tokens
+func TestTokenizer(t *testing.T) {
+	src := "<!-- c -->\n<!ATTLIST a %common; b (x|y) #IMPLIED>\n<![IGNORE[ <!ELEMENT z> ]]>"
+
+	tk := scanner.NewTokenizer("inline.dtd", strings.NewReader(src))
+	tokens := tokenize(t, tk)
+
+	expected := []struct {
+		typ   scanner.TokenType
+		value string
+	}{
+		{scanner.TokenDeclOpen, "ATTLIST"},
+		{scanner.TokenName, "a"},
+		{scanner.TokenPEReference, "common"},
+		{scanner.TokenName, "b"},
+		{scanner.TokenOpenParen, "("},
+		{scanner.TokenName, "x"},
+		{scanner.TokenPipe, "|"},
+		{scanner.TokenName, "y"},
+		{scanner.TokenCloseParen, ")"},
+		{scanner.TokenKeyword, "#IMPLIED"},
+		{scanner.TokenDeclClose, ">"},
+		{scanner.TokenSectionOpen, "<!["},
+		{scanner.TokenName, "IGNORE"},
+		{scanner.TokenOpenBracket, "["},
+		{scanner.TokenIgnored, " <!ELEMENT z> "},
+		{scanner.TokenSectionClose, "]]>"},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("Number of tokens (%d) differs from %d", len(tokens), len(expected))
+	}
+
+	for i, e := range expected {
+		t.Run("Check type of "+tokens[i].Text, checkStrValue(tokens[i].Type.String(), e.typ.String(), tokens[i], nil))
+		t.Run("Check value of "+tokens[i].Text, checkStrValue(tokens[i].Value, e.value, tokens[i], nil))
+	}
+
+	t.Run("Check reference position", checkPos(tokens[2].Pos, DTD.Pos{File: "inline.dtd", Offset: 23, Line: 2, Column: 13, EndOffset: 31, EndLine: 2, EndColumn: 21}))
+
+	tk = scanner.NewTokenizer("inline.dtd", strings.NewReader(src))
+	tk.WithComments = true
+	tokens = tokenize(t, tk)
+
+	t.Run("Check comment", checkStrValue(tokens[0].Type.String(), "Comment", tokens[0], nil))
+	t.Run("Check comment value", checkStrValue(tokens[0].Value, " c ", tokens[0], nil))
+}
+
+// TestTokenizerError Test the error returned on an invalid character
+func TestTokenizerError(t *testing.T) {
+	tk := scanner.NewTokenizer("inline.dtd", strings.NewReader("<!ELEMENT a\n  {b}>"))
+
+	var err error
+	for err == nil {
+		var tok scanner.Token
+		if tok, err = tk.Next(); tok.Type == scanner.TokenEOF && err == nil {
+			t.Fatalf("Tokenizing did not fail")
+		}
+	}
+
+	var syntaxErr *scanner.SyntaxError
+
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Error %v is not a SyntaxError", err)
+	}
+
+	t.Run("Check line", checkIntValue(syntaxErr.Line, 2, err, nil))
+	t.Run("Check column", checkIntValue(syntaxErr.Column, 3, err, nil))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blefort/DTDParser/DTD"
+)
+
+// recordVisitor records the nodes it visits
+type recordVisitor struct {
+	DTD.BaseVisitor
+	nodes       []string
+	skipElement bool
+}
+
+func (v *recordVisitor) VisitXMLDecl(x *DTD.XMLDecl) {
+	v.nodes = append(v.nodes, "xml")
+}
+
+func (v *recordVisitor) VisitComment(c *DTD.Comment) {
+	v.nodes = append(v.nodes, "comment")
+}
+
+func (v *recordVisitor) VisitElement(e *DTD.Element) bool {
+	v.nodes = append(v.nodes, "element:"+e.Name)
+	return !v.skipElement
+}
+
+func (v *recordVisitor) VisitParticle(p *DTD.Particle) bool {
+	v.nodes = append(v.nodes, "particle:"+p.Render())
+	return !p.IsGroup() || p.Occurrence == DTD.ONCE
+}
+
+func (v *recordVisitor) VisitAttribute(a *DTD.Attribute) {
+	v.nodes = append(v.nodes, "attribute:"+a.Name)
+}
+
+func (v *recordVisitor) VisitEntity(e *DTD.Entity) {
+	v.nodes = append(v.nodes, "entity:"+e.Name)
+}
+
+func (v *recordVisitor) VisitNotation(n *DTD.Notation) {
+	v.nodes = append(v.nodes, "notation:"+n.Name)
+}
+
+func (v *recordVisitor) VisitConditionalSection(c *DTD.ConditionalSection) bool {
+	v.nodes = append(v.nodes, "section:"+c.Keyword)
+	return true
+}
+
+// TestWalk Test the nodes visited by Walk
+func TestWalk(t *testing.T) {
+	p := newParser(t.TempDir())
+
+	src := "<?xml version=\"1.0\"?>\n" +
+		"<!-- doc -->\n" +
+		"<!ELEMENT doc (title, (para | list)*)>\n" +
+		"<!ATTLIST doc id ID #REQUIRED lang CDATA #IMPLIED>\n" +
+		"<![INCLUDE[\n" +
+		"<!ENTITY copy \"(c)\">\n" +
+		"<!NOTATION png SYSTEM \"image/png\">\n" +
+		"]]>\n" +
+		"<?pi?>"
+
+	if err := p.ParseReader("inline.dtd", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	var v recordVisitor
+	DTD.Walk(&v, p.Collection...)
+
+	expected := []string{
+		"xml",
+		"comment",
+		"element:doc",
+		"particle:(title,(para|list)*)",
+		"particle:title",
+		"particle:(para|list)*",
+		"attribute:id",
+		"attribute:lang",
+		"section:INCLUDE",
+		"entity:copy",
+		"notation:png",
+	}
+
+	t.Run("Check visited nodes", checkStrValue(strings.Join(v.nodes, " "), strings.Join(expected, " "), v.nodes, nil))
+
+	// children are skipped
+	v = recordVisitor{skipElement: true}
+	DTD.Walk(&v, p.Collection[2])
+
+	t.Run("Check skipped particles", checkStrValue(strings.Join(v.nodes, " "), "element:doc", v.nodes, nil))
+}